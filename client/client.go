@@ -6,19 +6,13 @@ import (
 	"errors"
 	"fmt"
 	"github.com/dasio/pcfg-manager/manager"
+	"github.com/dasio/pcfg-manager/output"
 	pb "github.com/dasio/pcfg-manager/proto"
 	"github.com/sirupsen/logrus"
 	"google.golang.org/grpc"
 	"io"
-	"io/ioutil"
 	"math"
-	"math/rand"
 	"os"
-	"os/exec"
-	"path/filepath"
-	"runtime"
-	"strings"
-	"sync"
 	"time"
 )
 
@@ -28,10 +22,26 @@ type Service struct {
 	grpcConn    *grpc.ClientConn
 	grammar     *manager.Grammar
 	genOnly     bool
-	hashFile    string
 	hashcatPath string
 	hashcatMode string
-	hashcatPipe io.WriteCloser
+	// output is sink of generated guesses in generate-only mode
+	output io.WriteCloser
+	// dedupe drops duplicate guesses of whole session, it's nil if deduplication is disabled
+	dedupe *manager.Deduper
+	// exclusion drops guesses which were already tried before cracking,
+	// it's loaded after hash list is received, because salted lines of potfile are split by hash list
+	exclusion        *manager.Exclusion
+	excludePotfiles  []string
+	excludeWordlists []string
+	// attack is manager.AttackPlain or manager.AttackRules
+	attack string
+	// backend is BackendHashcat, BackendNative or BackendFake
+	backend string
+	// cracker of backend, it's created after hash list is received
+	cracker       Cracker
+	nativeWorkers int
+	// progressInterval is how often status of cracker is sent to server
+	progressInterval time.Duration
 	// tmp
 	hashes          []string
 	start           time.Time
@@ -48,38 +58,76 @@ type InputArgs struct {
 	GenOnly       bool
 	GenRoutines   uint
 	SaveStats     bool
+	Output        string
+	Rotate        uint64
+	// Dedupe, DedupeItems and DedupeFalsePositive configure deduplication, see manager.NewDeduper
+	Dedupe              string
+	DedupeItems         uint64
+	DedupeFalsePositive float64
+	// ExcludePotfiles and ExcludeWordlists contain guesses which aren't passed to hashcat
+	ExcludePotfiles  []string
+	ExcludeWordlists []string
+	// Attack is manager.AttackRules if pre-terminals should be cracked by hashcat rules instead of plaintext
+	Attack string
+	// Backend selects Cracker, see newCracker
+	Backend       string
+	NativeWorkers int
+	// ProgressInterval is how often status of cracker is logged and sent to server
+	ProgressInterval time.Duration
 }
 
-const (
-	HsCodeGpuWatchdogAlarm    = -2
-	HsCodeError               = -1
-	HsCodeOk                  = 0
-	HsCodeExhausted           = 1
-	HsCodeAborted             = 2
-	HsCodeAbortedByCheckpoint = 3
-	HsCodeAbortedByRune       = 4
-)
-
 var (
-	ErrFinished = errors.New("server finished cracking")
+	ErrFinished       = errors.New("server finished cracking")
+	ErrUnknownAttack  = errors.New("unknown attack, expected plain or rules")
+	ErrUnknownBackend = errors.New("unknown backend, expected hashcat, native or fake")
+	ErrRulesBackend   = errors.New("rules attack requires hashcat backend")
 )
 
 func NewService(inArgs InputArgs) (*Service, error) {
 	var path string
 	var err error
-	if !inArgs.GenOnly {
-		path, err = filepath.Abs(inArgs.HashcatFolder + "/" + getHashcatBinary())
+	switch inArgs.Backend {
+	case BackendHashcat, BackendNative, BackendFake:
+	default:
+		return nil, ErrUnknownBackend
+	}
+	if inArgs.Backend != BackendHashcat && inArgs.Attack == manager.AttackRules {
+		return nil, ErrRulesBackend
+	}
+	if !inArgs.GenOnly && inArgs.Backend == BackendHashcat {
+		path, err = hashcatPath(inArgs.HashcatFolder)
 		if err != nil {
 			return nil, err
 		}
-		if _, err := os.Stat(path); os.IsNotExist(err) {
-			return nil, err
-		}
 	}
 	svc := &Service{
-		hashcatPath: path,
-		genOnly:     inArgs.GenOnly,
-		genRoutines: inArgs.GenRoutines,
+		hashcatPath:      path,
+		genOnly:          inArgs.GenOnly,
+		genRoutines:      inArgs.GenRoutines,
+		attack:           inArgs.Attack,
+		backend:          inArgs.Backend,
+		nativeWorkers:    inArgs.NativeWorkers,
+		progressInterval: inArgs.ProgressInterval,
+		excludePotfiles:  inArgs.ExcludePotfiles,
+		excludeWordlists: inArgs.ExcludeWordlists,
+	}
+	switch inArgs.Attack {
+	case manager.AttackPlain, manager.AttackRules:
+	default:
+		return nil, ErrUnknownAttack
+	}
+	if inArgs.Attack == manager.AttackRules && (inArgs.Dedupe != "" || len(inArgs.ExcludePotfiles) > 0 || len(inArgs.ExcludeWordlists) > 0) {
+		logrus.Warn("dedupe and exclusion are applied only to guesses which aren't cracked by rules")
+	}
+	svc.dedupe, err = manager.NewDeduper(inArgs.Dedupe, inArgs.DedupeItems, inArgs.DedupeFalsePositive)
+	if err != nil {
+		return nil, err
+	}
+	if inArgs.GenOnly {
+		svc.output, err = output.Open(inArgs.Output, inArgs.Rotate)
+		if err != nil {
+			return nil, err
+		}
 	}
 	return svc, nil
 }
@@ -107,6 +155,18 @@ func (s *Service) SaveStats() error {
 	if err != nil {
 		return err
 	}
+	if s.dedupe != nil {
+		_, err = f.WriteString(fmt.Sprintf("Dropped duplicates: %d\n", s.dedupe.Dropped()))
+		if err != nil {
+			return err
+		}
+	}
+	if status, ok := s.Status(); ok {
+		_, err = f.WriteString(fmt.Sprintf("Cracker speed: %f\n", status.Speed))
+		if err != nil {
+			return err
+		}
+	}
 	_, err = f.WriteString(fmt.Sprintf("Waiting for responses: %s\n", s.waitForResponse))
 	if err != nil {
 		return err
@@ -117,19 +177,6 @@ func (s *Service) SaveStats() error {
 	}
 	return f.Close()
 }
-func getHashcatBinary() string {
-	var ext string
-	if runtime.GOOS == "windows" {
-		ext = "exe"
-	} else {
-		ext = "bin"
-	}
-	arch := "32"
-	if strings.HasSuffix(runtime.GOARCH, "64") {
-		arch = "64"
-	}
-	return fmt.Sprintf("hashcat%s.%s", arch, ext)
-}
 
 func (s *Service) Connect(address string) error {
 	var err error
@@ -145,43 +192,78 @@ func (s *Service) Connect(address string) error {
 		return err
 	}
 	s.grammar = manager.GrammarFromProto(r.Grammar)
-	s.mng = manager.NewManager(s.grammar.RulesFolder)
-	s.mng.LoadWithGrammar(s.grammar)
-	f, err := ioutil.TempFile("", "pcfg-*.hash")
-	if err != nil {
+	if err := s.grammar.Validate(); err != nil {
 		return err
 	}
+	s.mng = manager.NewManager(s.grammar.RulesFolder)
+	s.mng.LoadWithGrammar(s.grammar)
 	// tmp
 	s.hashes = r.HashList
 	s.hashcatMode = r.HashcatMode
-	s.hashFile = f.Name()
-	if _, err := f.Write([]byte(strings.Join(r.HashList, "\n"))); err != nil {
-		return err
+	if len(s.excludePotfiles) > 0 || len(s.excludeWordlists) > 0 {
+		s.exclusion, err = manager.LoadExclusion(s.excludePotfiles, s.excludeWordlists, s.hashes)
+		if err != nil {
+			return err
+		}
+		logrus.Infof("loaded %d excluded guesses", s.exclusion.Len())
 	}
-	if err := f.Close(); err != nil {
-		return err
+	if !s.genOnly {
+		s.cracker, err = s.newCracker()
+		if err != nil {
+			return err
+		}
 	}
 	s.start = time.Now()
 
 	return nil
 }
 
-func (s *Service) startHashcat() (*exec.Cmd, error) {
-	cmd := exec.Command(s.hashcatPath, "-m", s.hashcatMode, "-o", "results.txt", "--machine-readable", "--status", s.hashFile)
-	cmd.Stdout = os.Stdout
-	cmd.Stderr = os.Stderr
-	pipe, err := cmd.StdinPipe()
-	if err != nil {
-		return nil, err
+// Status returns progress of cracker, it's false if cracker doesn't report it
+func (s *Service) Status() (Status, bool) {
+	if sc, ok := s.cracker.(StatusCracker); ok {
+		return sc.Status()
 	}
-	s.hashcatPipe = pipe
-	if err := cmd.Start(); err != nil {
-		return nil, err
+	return Status{}, false
+}
+
+// reportProgress periodically logs status of cracker and sends it to server until stop is closed
+func (s *Service) reportProgress(stop <-chan struct{}) {
+	sc, ok := s.cracker.(StatusCracker)
+	if !ok || s.progressInterval <= 0 {
+		return
+	}
+	ticker := time.NewTicker(s.progressInterval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ticker.C:
+		case <-stop:
+			return
+		}
+		status, ok := sc.Status()
+		if !ok {
+			continue
+		}
+		logrus.Infof("speed: %.0f guesses/s, guesses: %d, recovered: %d/%d",
+			status.Speed, status.Guesses, status.RecoveredHashes, status.TotalHashes)
+		ctx, cancel := context.WithTimeout(context.Background(), s.progressInterval)
+		_, err := s.c.SendProgress(ctx, &pb.Progress{
+			Speed:           status.Speed,
+			Guesses:         status.Guesses,
+			RecoveredHashes: status.RecoveredHashes,
+			TotalHashes:     status.TotalHashes,
+		})
+		cancel()
+		if err != nil {
+			logrus.Debug(err)
+		}
 	}
-	return cmd, nil
 }
 
 func (s *Service) Run(done <-chan bool) error {
+	stop := make(chan struct{})
+	defer close(stop)
+	go s.reportProgress(stop)
 	for {
 		select {
 		case <-done:
@@ -199,7 +281,14 @@ func (s *Service) Run(done <-chan bool) error {
 			logrus.Infof("received %d preTerminals and %d terminals", len(res.PreTerminals), len(res.Terminals))
 			cancel()
 			if len(res.PreTerminals) == 0 && len(res.Terminals) == 0 {
-				_, err = s.c.SendResult(context.Background(), &pb.CrackingResponse{})
+				results, err := s.lastResults()
+				if err != nil {
+					logrus.Warn(err)
+				}
+				logrus.Infof("sending %d cracked hashes", len(results))
+				_, err = s.c.SendResult(context.Background(), &pb.CrackingResponse{
+					Hashes: results,
+				})
 				if err != nil {
 					return err
 				}
@@ -235,33 +324,19 @@ func (s *Service) Run(done <-chan bool) error {
 	}
 }
 
-func (s *Service) worker(jobs <-chan *pb.TreeItem) {
-	for j := range jobs {
-		treeItem := manager.TreeItemFromProto(j)
-		err := s.mng.Generator.Pcfg.ListTerminalsToWriter(treeItem, os.Stdout)
-		if err != nil {
-			logrus.Warn(err)
-		}
-	}
-
-}
 func (s *Service) generateOnly(items *pb.Items) (map[string]string, error) {
-	jobs := make(chan *pb.TreeItem, s.genRoutines)
-	wg := sync.WaitGroup{}
-	wg.Add(int(s.genRoutines))
-	for w := 1; w <= int(s.genRoutines); w++ {
-		go func() {
-			s.worker(jobs)
-			wg.Done()
-		}()
-	}
+	preTerminals := make([]*manager.TreeItem, 0, len(items.PreTerminals))
 	for _, item := range items.PreTerminals {
-		jobs <- item
+		preTerminals = append(preTerminals, manager.TreeItemFromProto(item))
+	}
+	if err := s.mng.Generator.Pcfg.ListTerminalsParallel(preTerminals, s.output, s.genRoutines, s.dedupe); err != nil {
+		return nil, err
 	}
-	close(jobs)
-	wg.Wait()
-	buf := bufio.NewWriter(os.Stdout)
+	buf := bufio.NewWriter(s.output)
 	for _, t := range items.Terminals {
+		if s.dedupe != nil && !s.dedupe.Keep([]byte(t)) {
+			continue
+		}
 		if _, err := fmt.Fprintln(buf, t); err != nil {
 			return nil, err
 		}
@@ -272,77 +347,101 @@ func (s *Service) generateOnly(items *pb.Items) (map[string]string, error) {
 
 	return map[string]string{}, nil
 }
-func (s *Service) startCracking(items *pb.Items) (map[string]string, error) {
-	cmd, err := s.startHashcat()
-	if err != nil {
-		return nil, err
+
+// keep returns true if guess should be passed to cracker
+func (s *Service) keep(guess []byte) bool {
+	if s.exclusion != nil && !s.exclusion.Keep(guess) {
+		return false
 	}
+	return s.dedupe == nil || s.dedupe.Keep(guess)
+}
+
+func (s *Service) startCracking(items *pb.Items) (map[string]string, error) {
+	preTerminals := make([]*manager.TreeItem, 0, len(items.PreTerminals))
 	for _, item := range items.PreTerminals {
-		treeItem := manager.TreeItemFromProto(item)
-		err := s.mng.Generator.Pcfg.ListTerminalsToWriter(treeItem, s.hashcatPipe)
-		if err != nil {
-			return nil, err
-		}
-	}
-	buf := bufio.NewWriter(s.hashcatPipe)
-	for _, t := range items.Terminals {
-		if _, err := fmt.Fprintln(buf, t); err != nil {
-			return nil, err
-		}
+		preTerminals = append(preTerminals, manager.TreeItemFromProto(item))
 	}
-	if err := buf.Flush(); err != nil {
-		return nil, err
-	}
-
-	if err := s.hashcatPipe.Close(); err != nil {
+	if err := s.cracker.Start(); err != nil {
 		return nil, err
 	}
-	if err := cmd.Wait(); err != nil {
-		if exitErr, ok := err.(*exec.ExitError); ok {
-			if exitErr.ExitCode() != HsCodeOk && exitErr.ExitCode() != HsCodeExhausted {
+	if s.attack == manager.AttackRules {
+		rc, ok := s.cracker.(RuleCracker)
+		if !ok {
+			return nil, ErrRulesBackend
+		}
+		var attacks []*manager.RuleAttack
+		attacks, preTerminals = s.mng.Generator.Pcfg.CompileAttacks(preTerminals)
+		for _, attack := range attacks {
+			if err := rc.CrackRules(attack); err != nil {
 				return nil, err
 			}
 		}
 	}
-	results, err := getResults("results.txt")
+	var w io.Writer = feeder{c: s.cracker}
+	if s.dedupe != nil || s.exclusion != nil {
+		w = manager.NewFilterWriter(w, s.keep)
+	}
+	err := s.writeGuesses(w, preTerminals, items.Terminals)
+	// cracker is finished even after error, so it doesn't wait for more guesses
+	if fErr := s.cracker.Finish(); err == nil {
+		err = fErr
+	}
 	if err != nil {
 		return nil, err
 	}
-	return results, nil
+	return s.cracker.Results()
 }
 
-func getResults(path string) (map[string]string, error) {
-	file, err := os.Open(path)
-	if err != nil {
-		if err == os.ErrNotExist {
-			return map[string]string{}, nil
-		}
-		return nil, err
+// lastResults closes cracker and returns hashes which were cracked after last chunk was reported
+func (s *Service) lastResults() (map[string]string, error) {
+	if s.cracker == nil {
+		return map[string]string{}, nil
 	}
-	defer file.Close()
-
-	res := make(map[string]string)
-	scanner := bufio.NewScanner(file)
-	for scanner.Scan() {
-		split := strings.Split(scanner.Text(), ":")
-		if len(split) != 2 {
-			continue
-		}
-		res[split[0]] = split[1]
+	err := s.cracker.Close()
+	results, rErr := s.cracker.Results()
+	if err == nil {
+		err = rErr
 	}
-	return res, scanner.Err()
+	return results, err
 }
 
-func (s *Service) randomResult() map[string]string {
-	if rand.Float32() > 0.8 {
-		return map[string]string{
-			s.hashes[rand.Int()%len(s.hashes)]: "PassWord123",
+// writeGuesses writes guesses of pre-terminals and terminals to w
+func (s *Service) writeGuesses(w io.Writer, preTerminals []*manager.TreeItem, terminals []string) error {
+	// buffer is shared by all pre-terminals
+	buf := bufio.NewWriterSize(w, 64*1024)
+	for _, treeItem := range preTerminals {
+		if err := s.mng.Generator.Pcfg.ListTerminalsToWriter(treeItem, buf); err != nil {
+			return err
+		}
+	}
+	for _, t := range terminals {
+		if _, err := fmt.Fprintln(buf, t); err != nil {
+			return err
 		}
 	}
-	return map[string]string{}
+	return buf.Flush()
 }
 
 func (s *Service) Disconnect() error {
+	if s.dedupe != nil {
+		logrus.Infof("dropped %d duplicate guesses", s.dedupe.Dropped())
+	}
+	if s.exclusion != nil {
+		logrus.Infof("excluded %d already tried guesses", s.exclusion.Excluded())
+	}
+	// output is closed even if server is already gone, otherwise compressed output would be truncated
+	if s.output != nil {
+		if err := s.output.Close(); err != nil {
+			return err
+		}
+		s.output = nil
+	}
+	if s.cracker != nil {
+		if err := s.cracker.Close(); err != nil {
+			logrus.Warn(err)
+		}
+		s.cracker = nil
+	}
 	if s.grpcConn == nil {
 		return errors.New("no active grpc connection")
 	}
@@ -351,7 +450,6 @@ func (s *Service) Disconnect() error {
 	if _, err := s.c.Disconnect(ctx, &pb.Empty{}); err != nil {
 		return err
 	}
-	_ = os.Remove(s.hashFile)
 	if err := s.grpcConn.Close(); err != nil {
 		return err
 	}
@@ -0,0 +1,110 @@
+package client
+
+import (
+	"bytes"
+	"github.com/dasio/pcfg-manager/manager"
+	"math/rand"
+)
+
+const (
+	BackendHashcat = "hashcat"
+	BackendNative  = "native"
+	// BackendFake doesn't crack anything, it randomly reports hashes as cracked
+	BackendFake = "fake"
+)
+
+// Cracker cracks guesses of chunks received from server
+type Cracker interface {
+	// Start is called before guesses of chunk are fed
+	Start() error
+	// Feed passes guesses separated by new line, line can be split between calls
+	Feed(guesses []byte) error
+	// Finish is called after all guesses of chunk are fed, cracker can still crack them in background
+	Finish() error
+	// Results returns hashes cracked since last call as hash line -> password
+	Results() (map[string]string, error)
+	// Close waits until all fed guesses are cracked and releases resources of cracker at the end of session,
+	// cracks of last guesses are returned by following Results call
+	Close() error
+}
+
+// RuleCracker is Cracker which can crack pre-terminals compiled to words and hashcat rules
+type RuleCracker interface {
+	Cracker
+	CrackRules(attack *manager.RuleAttack) error
+}
+
+// Status is progress of cracker in session
+type Status struct {
+	// Speed is measured number of guesses per second
+	Speed           float64
+	Guesses         uint64
+	RecoveredHashes uint64
+	TotalHashes     uint64
+}
+
+// StatusCracker is Cracker which reports its progress
+type StatusCracker interface {
+	Cracker
+	// Status returns last progress, it's false if there is no progress yet
+	Status() (Status, bool)
+}
+
+// newCracker returns cracker of backend for hash list received from server
+func (s *Service) newCracker() (Cracker, error) {
+	switch s.backend {
+	case BackendNative:
+		return newNativeCracker(s.hashcatMode, s.hashes, s.nativeWorkers)
+	case BackendFake:
+		return &fakeCracker{hashes: s.hashes}, nil
+	default:
+		return newHashcatCracker(s.hashcatPath, s.hashcatMode, s.hashes)
+	}
+}
+
+// feeder is io.Writer which feeds guesses to cracker
+type feeder struct {
+	c Cracker
+}
+
+func (f feeder) Write(p []byte) (int, error) {
+	if err := f.c.Feed(p); err != nil {
+		return 0, err
+	}
+	return len(p), nil
+}
+
+// fakeCracker is backend for testing of server and client without cracking
+type fakeCracker struct {
+	hashes  []string
+	guesses int
+}
+
+func (f *fakeCracker) Start() error {
+	return nil
+}
+
+func (f *fakeCracker) Feed(guesses []byte) error {
+	f.guesses += bytes.Count(guesses, []byte{'\n'})
+	return nil
+}
+
+func (f *fakeCracker) Finish() error {
+	return nil
+}
+
+// Results reports random hash as cracked with probability 0.2 if any guess was fed since last call
+func (f *fakeCracker) Results() (map[string]string, error) {
+	fed := f.guesses
+	f.guesses = 0
+	if fed > 0 && len(f.hashes) > 0 && rand.Float32() > 0.8 {
+		return map[string]string{
+			f.hashes[rand.Int()%len(f.hashes)]: "PassWord123",
+		}, nil
+	}
+	return map[string]string{}, nil
+}
+
+func (f *fakeCracker) Close() error {
+	return nil
+}
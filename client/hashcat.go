@@ -0,0 +1,446 @@
+package client
+
+import (
+	"bufio"
+	"bytes"
+	"fmt"
+	"github.com/dasio/pcfg-manager/manager"
+	"github.com/sirupsen/logrus"
+	"io"
+	"io/ioutil"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"runtime"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+)
+
+const (
+	HsCodeGpuWatchdogAlarm    = -2
+	HsCodeError               = -1
+	HsCodeOk                  = 0
+	HsCodeExhausted           = 1
+	HsCodeAborted             = 2
+	HsCodeAbortedByCheckpoint = 3
+	HsCodeAbortedByRune       = 4
+)
+
+const (
+	// hashcatStatusTimer is how often hashcat prints status in seconds
+	hashcatStatusTimer = "1"
+	// hashcatStallTimeout is how long Finish waits for hashcat without progress,
+	// hashcat can keep last guesses of chunk in its buffer until next guesses are fed
+	hashcatStallTimeout = time.Second * 5
+)
+
+// HashcatStatus is STATUS line of hashcat with --machine-readable
+type HashcatStatus struct {
+	// Speed is number of hashes computed per second by all devices
+	Speed float64
+	// Progress is number of processed guesses multiplied by TotalSalts
+	Progress        uint64
+	RecoveredHashes uint64
+	TotalHashes     uint64
+	RecoveredSalts  uint64
+	TotalSalts      uint64
+	Rejected        uint64
+}
+
+func (s HashcatStatus) salts() uint64 {
+	if s.TotalSalts == 0 {
+		return 1
+	}
+	return s.TotalSalts
+}
+
+// Guesses returns number of processed guesses
+func (s HashcatStatus) Guesses() uint64 {
+	return s.Progress / s.salts()
+}
+
+// GuessSpeed returns number of processed guesses per second
+func (s HashcatStatus) GuessSpeed() float64 {
+	return s.Speed / float64(s.salts())
+}
+
+// ParseHashcatStatus parses tab separated STATUS line, fields which aren't known are skipped
+func ParseHashcatStatus(line string) (HashcatStatus, bool) {
+	fields := strings.Split(strings.TrimSpace(line), "\t")
+	if fields[0] != "STATUS" {
+		return HashcatStatus{}, false
+	}
+	var status HashcatStatus
+	pair := func(i int) (uint64, uint64) {
+		if i+2 >= len(fields) {
+			return 0, 0
+		}
+		a, _ := strconv.ParseUint(fields[i+1], 10, 64)
+		b, _ := strconv.ParseUint(fields[i+2], 10, 64)
+		return a, b
+	}
+	for i := 1; i < len(fields); i++ {
+		switch fields[i] {
+		case "SPEED":
+			// count of hashes and time in ms for every device
+			for i+2 < len(fields) {
+				count, err := strconv.ParseFloat(fields[i+1], 64)
+				if err != nil {
+					break
+				}
+				ms, err := strconv.ParseFloat(fields[i+2], 64)
+				if err != nil {
+					break
+				}
+				if ms > 0 {
+					status.Speed += count * 1000 / ms
+				}
+				i += 2
+			}
+		case "PROGRESS":
+			status.Progress, _ = pair(i)
+		case "RECHASH":
+			status.RecoveredHashes, status.TotalHashes = pair(i)
+		case "RECSALT":
+			status.RecoveredSalts, status.TotalSalts = pair(i)
+		case "REJECTED":
+			if i+1 < len(fields) {
+				status.Rejected, _ = strconv.ParseUint(fields[i+1], 10, 64)
+			}
+		}
+	}
+	return status, true
+}
+
+// hashcatCracker keeps one hashcat process for whole session, which reads guesses of all chunks from stdin
+type hashcatCracker struct {
+	path     string
+	mode     string
+	hashFile string
+	outfile  string
+	session  string
+	parser   *outfileParser
+	// offset is size of outfile which was already reported
+	offset int64
+	// pending are cracks of rule attacks which weren't reported yet, rule attacks have own outfile
+	pending map[string]string
+	// cmd is nil until first guesses are fed
+	cmd  *exec.Cmd
+	pipe io.WriteCloser
+	// fed is number of guesses passed to hashcat
+	fed uint64
+	// mu guards last status, updated is notified when status is parsed
+	mu     sync.Mutex
+	status HashcatStatus
+	// reported is true after first status is parsed
+	reported bool
+	updated  chan struct{}
+	// exited is closed when hashcat ends, waitErr is its result
+	exited  chan struct{}
+	waitErr error
+	// ended is true if hashcat ended before end of session, e.g. all hashes were cracked
+	ended  bool
+	closed bool
+}
+
+// hashcatPath returns absolute path of hashcat binary in folder
+func hashcatPath(folder string) (string, error) {
+	path, err := filepath.Abs(folder + "/" + getHashcatBinary())
+	if err != nil {
+		return "", err
+	}
+	if _, err := os.Stat(path); err != nil {
+		return "", err
+	}
+	return path, nil
+}
+
+func getHashcatBinary() string {
+	var ext string
+	if runtime.GOOS == "windows" {
+		ext = "exe"
+	} else {
+		ext = "bin"
+	}
+	arch := "32"
+	if strings.HasSuffix(runtime.GOARCH, "64") {
+		arch = "64"
+	}
+	return fmt.Sprintf("hashcat%s.%s", arch, ext)
+}
+
+// newHashcatCracker writes hash list to temporary file, which is removed by Close
+func newHashcatCracker(path, mode string, hashes []string) (*hashcatCracker, error) {
+	f, err := ioutil.TempFile("", "pcfg-*.hash")
+	if err != nil {
+		return nil, err
+	}
+	if _, err := f.Write([]byte(strings.Join(hashes, "\n"))); err != nil {
+		f.Close()
+		return nil, err
+	}
+	if err := f.Close(); err != nil {
+		return nil, err
+	}
+	h := &hashcatCracker{
+		path:     path,
+		mode:     mode,
+		hashFile: f.Name(),
+		outfile:  "results.txt",
+		session:  fmt.Sprintf("pcfg-%d", os.Getpid()),
+		parser:   newOutfileParser(hashes),
+		pending:  make(map[string]string),
+	}
+	// results of previous sessions aren't reported again
+	if info, err := os.Stat(h.outfile); err == nil {
+		h.offset = info.Size()
+	}
+	return h, nil
+}
+
+// start starts hashcat which reads guesses from stdin until Close,
+// it doesn't abort when server is slow with next chunk
+func (h *hashcatCracker) start() error {
+	cmd := exec.Command(h.path, "-m", h.mode, "-o", h.outfile, "--outfile-format", hashcatOutfileFormat,
+		"--machine-readable", "--status", "--status-timer", hashcatStatusTimer,
+		"--stdin-timeout-abort=0", "--session", h.session, h.hashFile)
+	cmd.Stderr = os.Stderr
+	pipe, err := cmd.StdinPipe()
+	if err != nil {
+		return err
+	}
+	stdout, err := cmd.StdoutPipe()
+	if err != nil {
+		return err
+	}
+	if err := cmd.Start(); err != nil {
+		return err
+	}
+	h.cmd, h.pipe = cmd, pipe
+	h.exited = make(chan struct{})
+	h.updated = make(chan struct{}, 1)
+	go func() {
+		// output has to be read before wait
+		h.readOutput(stdout)
+		h.waitErr = waitHashcat(cmd)
+		close(h.exited)
+	}()
+	return nil
+}
+
+// readOutput copies output of hashcat to stdout and keeps last status,
+// status lines are printed every second so they're only logged on debug level
+func (h *hashcatCracker) readOutput(r io.Reader) {
+	scanner := bufio.NewScanner(r)
+	for scanner.Scan() {
+		line := scanner.Text()
+		status, ok := ParseHashcatStatus(line)
+		if !ok {
+			fmt.Println(line)
+			continue
+		}
+		logrus.Debug(line)
+		h.mu.Lock()
+		h.status, h.reported = status, true
+		h.mu.Unlock()
+		select {
+		case h.updated <- struct{}{}:
+		default:
+		}
+	}
+	// rest of output isn't parsed, e.g. line is too long
+	_, _ = io.Copy(os.Stdout, r)
+}
+
+func (h *hashcatCracker) lastStatus() HashcatStatus {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	return h.status
+}
+
+// Status returns last status printed by hashcat
+func (h *hashcatCracker) Status() (Status, bool) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	return Status{
+		Speed:           h.status.GuessSpeed(),
+		Guesses:         h.status.Guesses(),
+		RecoveredHashes: h.status.RecoveredHashes,
+		TotalHashes:     h.status.TotalHashes,
+	}, h.reported
+}
+
+func (h *hashcatCracker) Start() error {
+	return nil
+}
+
+// Feed passes guesses to running hashcat, it's started with first guesses of session
+func (h *hashcatCracker) Feed(guesses []byte) error {
+	if h.ended || h.closed {
+		return nil
+	}
+	if h.cmd == nil {
+		if err := h.start(); err != nil {
+			return err
+		}
+	}
+	h.fed += uint64(bytes.Count(guesses, []byte{'\n'}))
+	if _, err := h.pipe.Write(guesses); err != nil {
+		// stdin is closed only when hashcat ends
+		<-h.exited
+		if h.waitErr != nil {
+			return h.waitErr
+		}
+		logrus.Info("hashcat ended, next guesses aren't cracked")
+		h.ended = true
+	}
+	return nil
+}
+
+// Finish waits until hashcat reports progress of all fed guesses, if it stalls,
+// cracks of chunk which are found later are reported with next chunks
+func (h *hashcatCracker) Finish() error {
+	if h.cmd == nil || h.ended {
+		return nil
+	}
+	progress := h.lastStatus().Progress
+	lastProgress := time.Now()
+	for {
+		status := h.lastStatus()
+		if status.Guesses() >= h.fed {
+			return nil
+		}
+		if status.Progress != progress {
+			progress, lastProgress = status.Progress, time.Now()
+		} else if time.Since(lastProgress) > hashcatStallTimeout {
+			logrus.Debugf("hashcat processed %d of %d guesses", status.Guesses(), h.fed)
+			return nil
+		}
+		select {
+		case <-h.exited:
+			h.ended = true
+			return h.waitErr
+		case <-h.updated:
+		case <-time.After(time.Second):
+		}
+	}
+}
+
+// Results returns hashes which were written to outfile since last call and cracks of rule attacks
+func (h *hashcatCracker) Results() (map[string]string, error) {
+	res := h.pending
+	h.pending = make(map[string]string)
+	file, err := os.Open(h.outfile)
+	if err != nil {
+		if os.IsNotExist(err) {
+			return res, nil
+		}
+		return nil, err
+	}
+	defer file.Close()
+	if _, err := file.Seek(h.offset, io.SeekStart); err != nil {
+		return nil, err
+	}
+	data, err := ioutil.ReadAll(file)
+	if err != nil {
+		return nil, err
+	}
+	// hashcat can be writing last line right now
+	data = data[:bytes.LastIndexByte(data, '\n')+1]
+	h.offset += int64(len(data))
+	for hash, plain := range h.parser.parseAll(data) {
+		res[hash] = plain
+	}
+	return res, nil
+}
+
+// Close waits until hashcat cracks all fed guesses and removes hash file
+func (h *hashcatCracker) Close() error {
+	if h.closed {
+		return nil
+	}
+	h.closed = true
+	var err error
+	if h.cmd != nil {
+		err = h.pipe.Close()
+		<-h.exited
+		if h.waitErr != nil {
+			err = h.waitErr
+		}
+	}
+	if rErr := os.Remove(h.hashFile); err == nil {
+		err = rErr
+	}
+	return err
+}
+
+// CrackRules runs hashcat with words and rules of attack written to temporary files,
+// it writes to own outfile, because hashcat reading stdin can write to outfile at the same time
+func (h *hashcatCracker) CrackRules(attack *manager.RuleAttack) error {
+	wordsFile, err := writeTempLines("pcfg-*.words", attack.Words)
+	if err != nil {
+		return err
+	}
+	defer os.Remove(wordsFile)
+	rulesFile, err := writeTempLines("pcfg-*.rule", attack.Rules)
+	if err != nil {
+		return err
+	}
+	defer os.Remove(rulesFile)
+	outfile, err := writeTempLines("pcfg-*.out", nil)
+	if err != nil {
+		return err
+	}
+	defer os.Remove(outfile)
+	cmd := exec.Command(h.path, "-m", h.mode, "-a", "0", "-r", rulesFile, "-o", outfile, "--outfile-format", hashcatOutfileFormat,
+		"--machine-readable", "--status", "--session", h.session+"-rules", h.hashFile, wordsFile)
+	cmd.Stdout = os.Stdout
+	cmd.Stderr = os.Stderr
+	if err := cmd.Start(); err != nil {
+		return err
+	}
+	if err := waitHashcat(cmd); err != nil {
+		return err
+	}
+	data, err := ioutil.ReadFile(outfile)
+	if err != nil {
+		return err
+	}
+	for hash, plain := range h.parser.parseAll(data) {
+		h.pending[hash] = plain
+	}
+	return nil
+}
+
+func writeTempLines(pattern string, lines []string) (string, error) {
+	f, err := ioutil.TempFile("", pattern)
+	if err != nil {
+		return "", err
+	}
+	buf := bufio.NewWriter(f)
+	for _, l := range lines {
+		if _, err := fmt.Fprintln(buf, l); err != nil {
+			f.Close()
+			return "", err
+		}
+	}
+	if err := buf.Flush(); err != nil {
+		f.Close()
+		return "", err
+	}
+	return f.Name(), f.Close()
+}
+
+// waitHashcat waits until hashcat ends, exhausted guesses aren't error
+func waitHashcat(cmd *exec.Cmd) error {
+	if err := cmd.Wait(); err != nil {
+		if exitErr, ok := err.(*exec.ExitError); ok {
+			if exitErr.ExitCode() != HsCodeOk && exitErr.ExitCode() != HsCodeExhausted {
+				return err
+			}
+		}
+	}
+	return nil
+}
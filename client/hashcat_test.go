@@ -0,0 +1,68 @@
+package client
+
+import "testing"
+
+func TestParseHashcatStatus(t *testing.T) {
+	tests := []struct {
+		name    string
+		line    string
+		ok      bool
+		status  HashcatStatus
+		guesses uint64
+	}{
+		{
+			name: "one device",
+			line: "STATUS\t3\tSPEED\t23716\t1000\tEXEC_RUNTIME\t0.081627\tCURKU\t0\tPROGRESS\t28672\t0\tRECHASH\t1\t3\tRECSALT\t1\t1\tREJECTED\t0\tUTIL\t26\t",
+			ok:   true,
+			status: HashcatStatus{
+				Speed:           23716,
+				Progress:        28672,
+				RecoveredHashes: 1,
+				TotalHashes:     3,
+				RecoveredSalts:  1,
+				TotalSalts:      1,
+			},
+			guesses: 28672,
+		},
+		{
+			name: "two devices and salts",
+			line: "STATUS\t3\tSPEED\t1000\t500\t3000\t1000\tEXEC_RUNTIME\t1.5\t2.1\tCURKU\t1024\tPROGRESS\t4096\t14344384\tRECHASH\t0\t4\tRECSALT\t0\t4\tTEMP\t61\t58\tREJECTED\t12\tUTIL\t100\t97",
+			ok:   true,
+			status: HashcatStatus{
+				Speed:       5000,
+				Progress:    4096,
+				TotalHashes: 4,
+				TotalSalts:  4,
+				Rejected:    12,
+			},
+			guesses: 1024,
+		},
+		{
+			name: "exhausted",
+			line: "STATUS\t5\tSPEED\t0\t1000\tEXEC_RUNTIME\t0.000000\tCURKU\t0\tPROGRESS\t366\t366\tRECHASH\t3\t3\tRECSALT\t1\t1\tREJECTED\t0\tUTIL\t-1\t",
+			ok:   true,
+			status: HashcatStatus{
+				Progress:        366,
+				RecoveredHashes: 3,
+				TotalHashes:     3,
+				RecoveredSalts:  1,
+				TotalSalts:      1,
+			},
+			guesses: 366,
+		},
+		{name: "header", line: "Session..........: hashcat"},
+		{name: "crack", line: "5f4dcc3b5aa765d61d8327deb882cf99:password"},
+		{name: "empty", line: ""},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			status, ok := ParseHashcatStatus(tt.line)
+			if ok != tt.ok || status != tt.status {
+				t.Fatalf("ParseHashcatStatus() = %+v, %t, want %+v, %t", status, ok, tt.status, tt.ok)
+			}
+			if got := status.Guesses(); got != tt.guesses {
+				t.Errorf("Guesses() = %d, want %d", got, tt.guesses)
+			}
+		})
+	}
+}
@@ -0,0 +1,327 @@
+package client
+
+import (
+	"bytes"
+	"crypto/md5"
+	"crypto/sha1"
+	"crypto/sha256"
+	"crypto/sha512"
+	"encoding/hex"
+	"errors"
+	"github.com/sirupsen/logrus"
+	"golang.org/x/crypto/bcrypt"
+	"golang.org/x/crypto/md4"
+	"hash"
+	"strings"
+	"sync"
+	"unicode"
+	"unicode/utf16"
+)
+
+const (
+	// nativeChunkSize is size of guesses chunk passed to hashing workers
+	nativeChunkSize = 64 * 1024
+	bcryptMode      = "3200"
+)
+
+var (
+	ErrUnsupportedMode   = errors.New("hashcat mode isn't supported by native backend")
+	ErrNoSupportedHash   = errors.New("hash list doesn't contain hash supported by native backend")
+	errInvalidNativeHash = errors.New("invalid hash")
+	errInvalidNativeSalt = errors.New("missing salt")
+)
+
+// nativeMode describes how hashcat mode is computed
+type nativeMode struct {
+	newHash func() hash.Hash
+	salted  bool
+	// saltFirst is true for $salt.$pass modes, otherwise salt is appended to password
+	saltFirst bool
+	// utf16 hashes password encoded as UTF-16LE (NTLM)
+	utf16 bool
+}
+
+// nativeModes are supported hashcat modes except bcrypt
+var nativeModes = map[string]nativeMode{
+	"0":    {newHash: md5.New},
+	"10":   {newHash: md5.New, salted: true},
+	"20":   {newHash: md5.New, salted: true, saltFirst: true},
+	"100":  {newHash: sha1.New},
+	"110":  {newHash: sha1.New, salted: true},
+	"120":  {newHash: sha1.New, salted: true, saltFirst: true},
+	"1000": {newHash: md4.New, utf16: true},
+	"1400": {newHash: sha256.New},
+	"1410": {newHash: sha256.New, salted: true},
+	"1420": {newHash: sha256.New, salted: true, saltFirst: true},
+	"1700": {newHash: sha512.New},
+	"1710": {newHash: sha512.New, salted: true},
+	"1720": {newHash: sha512.New, salted: true, saltFirst: true},
+}
+
+// saltGroup contains digests of hashes with the same salt
+type saltGroup struct {
+	salt []byte
+	// lines maps digest to lines of hash list
+	lines map[string][]string
+}
+
+// nativeCracker cracks hash list without hashcat by multiple goroutines
+type nativeCracker struct {
+	mode    nativeMode
+	bcrypt  bool
+	groups  []*saltGroup
+	bcrypts []string
+	workers int
+	// mu guards results and cracked hashes
+	mu      sync.Mutex
+	results map[string]string
+	cracked map[string]bool
+	// sender passes fed guesses to workers of chunk
+	sender *chunkSender
+	wg     sync.WaitGroup
+}
+
+// newNativeCracker parses hash list in format of hashcat mode, lines which can't be parsed are skipped
+func newNativeCracker(hashcatMode string, hashes []string, workers int) (*nativeCracker, error) {
+	if workers < 1 {
+		workers = 1
+	}
+	c := &nativeCracker{
+		workers: workers,
+		cracked: make(map[string]bool),
+		results: make(map[string]string),
+	}
+	mode, ok := nativeModes[hashcatMode]
+	if hashcatMode == bcryptMode {
+		c.bcrypt = true
+	} else if !ok {
+		return nil, ErrUnsupportedMode
+	}
+	c.mode = mode
+	groups := make(map[string]*saltGroup)
+	skipped := 0
+	for _, line := range hashes {
+		line = strings.TrimSpace(line)
+		if line == "" {
+			continue
+		}
+		if c.bcrypt {
+			if _, err := bcrypt.Cost([]byte(line)); err != nil {
+				skipped++
+				continue
+			}
+			c.bcrypts = append(c.bcrypts, line)
+			continue
+		}
+		digest, salt, err := c.parse(line)
+		if err != nil {
+			skipped++
+			continue
+		}
+		g, ok := groups[string(salt)]
+		if !ok {
+			g = &saltGroup{salt: salt, lines: make(map[string][]string)}
+			groups[string(salt)] = g
+			c.groups = append(c.groups, g)
+		}
+		g.lines[string(digest)] = append(g.lines[string(digest)], line)
+	}
+	if skipped > 0 {
+		logrus.Warnf("skipped %d hashes which aren't valid for mode %s", skipped, hashcatMode)
+	}
+	if len(c.groups) == 0 && len(c.bcrypts) == 0 {
+		return nil, ErrNoSupportedHash
+	}
+	return c, nil
+}
+
+// parse returns digest and salt of hash line, salt is separated by first colon
+func (c *nativeCracker) parse(line string) ([]byte, []byte, error) {
+	hexDigest, salt := line, ""
+	if c.mode.salted {
+		i := strings.IndexByte(line, ':')
+		if i == -1 {
+			return nil, nil, errInvalidNativeSalt
+		}
+		hexDigest, salt = line[:i], line[i+1:]
+	}
+	digest, err := hex.DecodeString(hexDigest)
+	if err != nil {
+		return nil, nil, err
+	}
+	if len(digest) != c.mode.newHash().Size() {
+		return nil, nil, errInvalidNativeHash
+	}
+	return digest, []byte(salt), nil
+}
+
+// Start starts workers which crack fed guesses until Finish is called
+func (c *nativeCracker) Start() error {
+	chunks := make(chan []byte, c.workers)
+	c.sender = &chunkSender{out: chunks}
+	c.wg.Add(c.workers)
+	for i := 0; i < c.workers; i++ {
+		go func() {
+			if c.bcrypt {
+				c.bcryptWorker(chunks)
+			} else {
+				c.worker(chunks)
+			}
+			c.wg.Done()
+		}()
+	}
+	return nil
+}
+
+func (c *nativeCracker) Feed(guesses []byte) error {
+	_, err := c.sender.Write(guesses)
+	return err
+}
+
+// Finish waits until workers crack last chunk
+func (c *nativeCracker) Finish() error {
+	if c.sender == nil {
+		return nil
+	}
+	err := c.sender.Close()
+	c.sender = nil
+	c.wg.Wait()
+	return err
+}
+
+// Results returns hashes cracked since last call, guesses of chunk are already cracked by Finish
+func (c *nativeCracker) Results() (map[string]string, error) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	res := c.results
+	c.results = make(map[string]string)
+	return res, nil
+}
+
+func (c *nativeCracker) Close() error {
+	return c.Finish()
+}
+
+func (c *nativeCracker) worker(chunks <-chan []byte) {
+	h := c.mode.newHash()
+	var input, sum []byte
+	var utf []uint16
+	for chunk := range chunks {
+		for len(chunk) > 0 {
+			var guess []byte
+			if guess, chunk = nextGuess(chunk); len(guess) == 0 {
+				continue
+			}
+			password := guess
+			if c.mode.utf16 {
+				password, utf = encodeUTF16(password, input[:0], utf)
+				input = password
+			}
+			for _, g := range c.groups {
+				h.Reset()
+				if c.mode.saltFirst {
+					h.Write(g.salt)
+				}
+				h.Write(password)
+				if c.mode.salted && !c.mode.saltFirst {
+					h.Write(g.salt)
+				}
+				sum = h.Sum(sum[:0])
+				if lines, ok := g.lines[string(sum)]; ok {
+					c.found(lines, guess)
+				}
+			}
+		}
+	}
+}
+
+func (c *nativeCracker) bcryptWorker(chunks <-chan []byte) {
+	for chunk := range chunks {
+		for len(chunk) > 0 {
+			var guess []byte
+			if guess, chunk = nextGuess(chunk); len(guess) == 0 {
+				continue
+			}
+			for _, line := range c.bcrypts {
+				if c.isCracked(line) {
+					continue
+				}
+				if bcrypt.CompareHashAndPassword([]byte(line), guess) == nil {
+					c.found([]string{line}, guess)
+				}
+			}
+		}
+	}
+}
+
+// nextGuess splits first line of chunk, empty line is returned as empty guess and workers skip it,
+// it's only separator of guesses, e.g. after chunk which ends with new line
+func nextGuess(chunk []byte) ([]byte, []byte) {
+	if i := bytes.IndexByte(chunk, '\n'); i != -1 {
+		return chunk[:i], chunk[i+1:]
+	}
+	return chunk, nil
+}
+
+func (c *nativeCracker) isCracked(line string) bool {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return c.cracked[line]
+}
+
+// found reports lines cracked by guess, hash is reported only once per session
+func (c *nativeCracker) found(lines []string, guess []byte) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	for _, line := range lines {
+		if c.cracked[line] {
+			continue
+		}
+		c.cracked[line] = true
+		c.results[line] = string(guess)
+	}
+}
+
+// encodeUTF16 returns password as UTF-16LE, buffers are reused
+func encodeUTF16(password, out []byte, utf []uint16) ([]byte, []uint16) {
+	utf = utf[:0]
+	for _, r := range string(password) {
+		if r1, r2 := utf16.EncodeRune(r); r1 != unicode.ReplacementChar {
+			utf = append(utf, uint16(r1), uint16(r2))
+		} else {
+			utf = append(utf, uint16(r))
+		}
+	}
+	for _, u := range utf {
+		out = append(out, byte(u), byte(u>>8))
+	}
+	return out, utf
+}
+
+// chunkSender passes guesses to hashing workers in chunks which end with whole line
+type chunkSender struct {
+	out chan<- []byte
+	buf []byte
+}
+
+func (w *chunkSender) Write(p []byte) (int, error) {
+	w.buf = append(w.buf, p...)
+	if len(w.buf) >= nativeChunkSize {
+		i := bytes.LastIndexByte(w.buf, '\n')
+		if i != -1 {
+			rest := w.buf[i+1:]
+			w.out <- w.buf[:i+1]
+			w.buf = append(make([]byte, 0, nativeChunkSize+4096), rest...)
+		}
+	}
+	return len(p), nil
+}
+
+func (w *chunkSender) Close() error {
+	if len(w.buf) > 0 {
+		w.out <- w.buf
+		w.buf = nil
+	}
+	close(w.out)
+	return nil
+}
@@ -0,0 +1,81 @@
+package client
+
+import (
+	"golang.org/x/crypto/bcrypt"
+	"reflect"
+	"testing"
+)
+
+// crack feeds chunks of guesses to native cracker and returns cracked hashes
+func crack(t *testing.T, mode string, hashes []string, chunks ...string) map[string]string {
+	t.Helper()
+	c, err := newNativeCracker(mode, hashes, 2)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := c.Start(); err != nil {
+		t.Fatal(err)
+	}
+	for _, chunk := range chunks {
+		if err := c.Feed([]byte(chunk)); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if err := c.Finish(); err != nil {
+		t.Fatal(err)
+	}
+	res, err := c.Results()
+	if err != nil {
+		t.Fatal(err)
+	}
+	return res
+}
+
+func TestNativeCracker(t *testing.T) {
+	tests := []struct {
+		mode    string
+		hash    string
+		plain   string
+		guesses string
+	}{
+		{"0", "5f4dcc3b5aa765d61d8327deb882cf99", "password", "pass\npassword\n"},
+		{"10", "22915b927ee30e153090c4a9310bc405:salt", "pa:ss", "pass\npa:ss\n"},
+		{"100", "5baa61e4c9b93f3f0682250b6cf8331b7ee68fd8", "password", "love\npassword"},
+		{"120", "59b3e8d637cf97edbe2384cf59cb7453dfe30789:salt", "password", "password\n"},
+		{"1000", "8846F7EAEE8FB117AD06BDD830B7586C", "password", "password\n"},
+		{"1400", "5e884898da28047151d0e56f8dc6292773603d0d6aabbdd62a11ef721d1542d8", "password", "password\n"},
+		{"1700", "b109f3bbbc244eb82441917ed06d618b9008dd09b3befd1b5e07394c706a8bb980b1d7785e5976ec049b46df5f1326af5a2ea6d103fd07c95385ffab0cacbc86", "password", "password\n"},
+		// test vector of OpenBSD bcrypt
+		{"3200", "$2a$05$CCCCCCCCCCCCCCCCCCCCC.E5YPO9kmyuRGyh0XouQYb4YMJKvyOeW", "U*U", "U*V\nU*U\n"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.mode, func(t *testing.T) {
+			// guesses are split between feeds
+			half := len(tt.guesses) / 2
+			got := crack(t, tt.mode, []string{tt.hash, "invalid"}, tt.guesses[:half], tt.guesses[half:])
+			if want := map[string]string{tt.hash: tt.plain}; !reflect.DeepEqual(got, want) {
+				t.Errorf("cracked %v, want %v", got, want)
+			}
+		})
+	}
+	if _, err := newNativeCracker("2500", nil, 1); err != ErrUnsupportedMode {
+		t.Errorf("newNativeCracker of unsupported mode = %v, want %v", err, ErrUnsupportedMode)
+	}
+	if _, err := newNativeCracker("0", []string{"invalid"}, 1); err != ErrNoSupportedHash {
+		t.Errorf("newNativeCracker without valid hash = %v, want %v", err, ErrNoSupportedHash)
+	}
+}
+
+func TestNativeCrackerEmptyGuess(t *testing.T) {
+	const emptyMD5 = "d41d8cd98f00b204e9800998ecf8427e"
+	if got := crack(t, "0", []string{emptyMD5}, "\npass\n\n", "\n"); len(got) != 0 {
+		t.Errorf("empty lines cracked %v", got)
+	}
+	emptyBcrypt, err := bcrypt.GenerateFromPassword(nil, bcrypt.MinCost)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got := crack(t, bcryptMode, []string{string(emptyBcrypt)}, "\npass\n\n", "\n"); len(got) != 0 {
+		t.Errorf("empty lines cracked %v", got)
+	}
+}
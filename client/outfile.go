@@ -0,0 +1,63 @@
+package client
+
+import (
+	"bytes"
+	"github.com/dasio/pcfg-manager/manager"
+	"github.com/sirupsen/logrus"
+	"strings"
+)
+
+// hashcatOutfileFormat is hash[:salt]:plain, it's default of hashcat, but outfile is parsed only in this format
+const hashcatOutfileFormat = "3"
+
+// outfileParser parses lines of hashcat outfile in hash[:salt]:plain format,
+// hash can contain colons (salt), so lines are matched with hash list
+type outfileParser struct {
+	// hashes maps lowercase lines of hash list to original lines, hashcat can print hash in different case
+	hashes map[string]string
+}
+
+func newOutfileParser(hashes []string) *outfileParser {
+	p := &outfileParser{hashes: make(map[string]string, len(hashes))}
+	for _, h := range hashes {
+		h = strings.TrimSpace(h)
+		if h != "" {
+			p.hashes[strings.ToLower(h)] = h
+		}
+	}
+	return p
+}
+
+// parse returns line of hash list and decoded password, password can contain colons or be encoded as $HEX[...]
+func (p *outfileParser) parse(line string) (string, string, bool) {
+	hash, plain, ok := manager.SplitHashLine(strings.TrimSuffix(line, "\r"), p.known)
+	if !ok {
+		return "", "", false
+	}
+	if h, ok := p.hashes[strings.ToLower(hash)]; ok {
+		hash = h
+	}
+	return hash, plain, true
+}
+
+func (p *outfileParser) known(hash string) bool {
+	_, ok := p.hashes[strings.ToLower(hash)]
+	return ok
+}
+
+// parseAll parses lines of outfile, invalid lines are skipped
+func (p *outfileParser) parseAll(data []byte) map[string]string {
+	res := make(map[string]string)
+	for _, line := range bytes.Split(data, []byte{'\n'}) {
+		if len(line) == 0 {
+			continue
+		}
+		hash, plain, ok := p.parse(string(line))
+		if !ok {
+			logrus.Warnf("invalid line of outfile: %s", line)
+			continue
+		}
+		res[hash] = plain
+	}
+	return res
+}
@@ -0,0 +1,56 @@
+package client
+
+import (
+	"reflect"
+	"testing"
+)
+
+const (
+	md5Hash       = "5f4dcc3b5aa765d61d8327deb882cf99"
+	saltedMD5Hash = "22915b927ee30e153090c4a9310bc405:salt"
+)
+
+func TestOutfileParser(t *testing.T) {
+	p := newOutfileParser([]string{md5Hash, saltedMD5Hash + "\r", "b305cadbb3bce54f3aa59c64fec00dea:sa:lt", ""})
+	tests := []struct {
+		name  string
+		line  string
+		hash  string
+		plain string
+		ok    bool
+	}{
+		{"unsalted", md5Hash + ":password", md5Hash, "password", true},
+		{"upper case hash", "5F4DCC3B5AA765D61D8327DEB882CF99:password", md5Hash, "password", true},
+		{"colon in plaintext", md5Hash + ":pa:ss", md5Hash, "pa:ss", true},
+		{"salted", saltedMD5Hash + ":pa:ss", saltedMD5Hash, "pa:ss", true},
+		{"colon in salt", "b305cadbb3bce54f3aa59c64fec00dea:sa:lt:pass", "b305cadbb3bce54f3aa59c64fec00dea:sa:lt", "pass", true},
+		{"hex plaintext", md5Hash + ":$HEX[70613a7373]", md5Hash, "pa:ss", true},
+		{"windows line", md5Hash + ":password\r", md5Hash, "password", true},
+		// hash isn't in list, so line is split at first colon
+		{"unknown hash", "d41d8cd98f00b204e9800998ecf8427e:salt:pass", "d41d8cd98f00b204e9800998ecf8427e", "salt:pass", true},
+		{"without colon", "password", "", "", false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			hash, plain, ok := p.parse(tt.line)
+			if hash != tt.hash || plain != tt.plain || ok != tt.ok {
+				t.Errorf("parse(%q) = %q, %q, %t, want %q, %q, %t", tt.line, hash, plain, ok, tt.hash, tt.plain, tt.ok)
+			}
+		})
+	}
+}
+
+func TestOutfileParserParseAll(t *testing.T) {
+	p := newOutfileParser([]string{md5Hash, saltedMD5Hash})
+	outfile := md5Hash + ":pass:word\n" +
+		"invalid line\n" +
+		"\n" +
+		saltedMD5Hash + ":$HEX[70613a7373]\r\n"
+	want := map[string]string{
+		md5Hash:       "pass:word",
+		saltedMD5Hash: "pa:ss",
+	}
+	if got := p.parseAll([]byte(outfile)); !reflect.DeepEqual(got, want) {
+		t.Errorf("parseAll() = %v, want %v", got, want)
+	}
+}
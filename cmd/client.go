@@ -2,11 +2,14 @@ package cmd
 
 import (
 	"github.com/dasio/pcfg-manager/client"
+	"github.com/dasio/pcfg-manager/manager"
 	"github.com/sirupsen/logrus"
 	"github.com/spf13/cobra"
 	"os"
 	"os/signal"
+	"runtime"
 	"syscall"
+	"time"
 )
 
 var (
@@ -18,7 +21,18 @@ func init() {
 	clientCmd.Flags().StringVarP(&clientArgs.ServerAddress, "server", "s", "localhost:50051", "server address")
 	clientCmd.Flags().StringVar(&clientArgs.HashcatFolder, "hashcat-folder", "./hashcat", "folder in which is hashcat binary")
 	clientCmd.Flags().BoolVar(&clientArgs.GenOnly, "generate-only", false, "generation guesses without cracking")
+	clientCmd.Flags().StringVar(&clientArgs.Attack, "attack", manager.AttackPlain, "how pre-terminals are cracked: plain (guesses piped to hashcat) or rules (words and hashcat rules, plain for other pre-terminals)")
+	clientCmd.Flags().StringVar(&clientArgs.Backend, "backend", client.BackendHashcat, "how hashes are cracked: hashcat, native (Go implementation of md5, sha1, sha256, sha512, NTLM, their salted variants and bcrypt) or fake (random results for testing)")
+	clientCmd.Flags().IntVar(&clientArgs.NativeWorkers, "native-workers", runtime.NumCPU(), "how many go routines crack hashes in native backend")
+	clientCmd.Flags().DurationVar(&clientArgs.ProgressInterval, "progress-interval", time.Second*10, "how often is status of hashcat logged and sent to server (0 = never)")
 	clientCmd.Flags().BoolVar(&clientArgs.SaveStats, "stats", false, "save stats after end")
+	clientCmd.Flags().StringVar(&clientArgs.Output, "output", "-", "where guesses are written in generate-only mode: - (stdout), file:path, gzip:path, zstd:path, fifo:path or tcp:host:port")
+	clientCmd.Flags().StringSliceVar(&clientArgs.ExcludePotfiles, "exclude-potfile", nil, "drop guesses cracked in hashcat potfile (can be repeated), guesses are kept as 64-bit hashes, so unique guess is dropped with probability n/2^64 for n excluded guesses")
+	clientCmd.Flags().StringSliceVar(&clientArgs.ExcludeWordlists, "exclude-wordlist", nil, "drop guesses contained in wordlist, e.g. of previous run (can be repeated), guesses are kept as 64-bit hashes, so unique guess is dropped with probability n/2^64 for n excluded guesses")
+	clientCmd.Flags().StringVar(&clientArgs.Dedupe, "dedupe", "", "drop duplicate guesses using exact set or bloom filter (exact, bloom)")
+	clientCmd.Flags().Uint64Var(&clientArgs.DedupeItems, "dedupe-items", 100000000, "expected number of guesses, bloom filter is sized for it")
+	clientCmd.Flags().Float64Var(&clientArgs.DedupeFalsePositive, "dedupe-fp", 0.001, "false positive rate of bloom filter, unique guesses are dropped with this rate")
+	clientCmd.Flags().Uint64Var(&clientArgs.Rotate, "rotate", 0, "split output file after every n guesses (0 = no rotation)")
 
 }
 
@@ -42,6 +56,8 @@ var clientCmd = &cobra.Command{
 		signal.Notify(sigs, syscall.SIGINT, syscall.SIGTERM)
 		go func() {
 			<-sigs
+			// next signal kills client immediately
+			signal.Stop(sigs)
 			done <- true
 			if clientArgs.SaveStats {
 				_ = svc.SaveStats()
@@ -0,0 +1,52 @@
+package cmd
+
+import (
+	"bufio"
+	"fmt"
+	"github.com/dasio/pcfg-manager/manager"
+	"github.com/spf13/cobra"
+	"os"
+)
+
+var (
+	explainCount int
+)
+
+func init() {
+	rootCmd.AddCommand(explainCmd)
+	explainCmd.Flags().IntVarP(&explainCount, "count", "n", 10, "how many pre-terminals are explained")
+}
+
+var explainCmd = &cobra.Command{
+	Use:   "explain",
+	Short: "Print top pre-terminals in priority order with structure, probability, number of guesses and first and last guess",
+	Long:  "Print top pre-terminals in priority order with structure, probability, number of guesses and first and last guess",
+	RunE: func(cmd *cobra.Command, args []string) error {
+		g, err := manager.LoadGrammar(rulesFolder)
+		if err != nil {
+			return err
+		}
+		pcfg := manager.NewPcfg(g)
+		que, err := manager.NewPcfgQueue(pcfg)
+		if err != nil {
+			return err
+		}
+		buf := bufio.NewWriter(os.Stdout)
+		for i := 1; i <= explainCount; i++ {
+			item, err := que.Next()
+			if err == manager.ErrPriorirtyQueEmpty {
+				break
+			}
+			if err != nil {
+				return err
+			}
+			guessGeneration := manager.NewGuessGeneration(g, item.Tree)
+			count := guessGeneration.Count()
+			first := guessGeneration.First()
+			last := guessGeneration.Seek(count - 1)
+			fmt.Fprintf(buf, "#%d %s p=%g guesses=%d first=%q last=%q\n", i, pcfg.BaseStructure(item.Tree), item.Probability, count, first, last)
+			fmt.Fprint(buf, pcfg.DescribeStructure(item.Tree))
+		}
+		return buf.Flush()
+	},
+}
@@ -0,0 +1,94 @@
+package cmd
+
+import (
+	"bufio"
+	"fmt"
+	"github.com/dasio/pcfg-manager/manager"
+	"github.com/spf13/cobra"
+	"os"
+	"strconv"
+	"strings"
+)
+
+func init() {
+	rootCmd.AddCommand(rankCmd)
+	rootCmd.AddCommand(unrankCmd)
+}
+
+// readArgs returns arguments or lines of stdin if there are no arguments
+func readArgs(args []string) ([]string, error) {
+	if len(args) > 0 {
+		return args, nil
+	}
+	var lines []string
+	scanner := bufio.NewScanner(os.Stdin)
+	for scanner.Scan() {
+		lines = append(lines, strings.TrimSuffix(scanner.Text(), "\r"))
+	}
+	return lines, scanner.Err()
+}
+
+var rankCmd = &cobra.Command{
+	Use:   "rank [passwords...]",
+	Short: "Print position (0-based) of passwords in generation order, reads stdin if no password is specified",
+	Long:  "Print position (0-based) of passwords in generation order, reads stdin if no password is specified",
+	RunE: func(cmd *cobra.Command, args []string) error {
+		g, err := manager.LoadGrammar(rulesFolder)
+		if err != nil {
+			return err
+		}
+		pcfg := manager.NewPcfg(g)
+		passwords, err := readArgs(args)
+		if err != nil {
+			return err
+		}
+		// all passwords are ranked by one walk of pre-terminals
+		positions, errs := pcfg.RankAll(passwords)
+		buf := bufio.NewWriter(os.Stdout)
+		defer buf.Flush()
+		for i, password := range passwords {
+			if errs[i] != nil {
+				fmt.Fprintf(buf, "%s\t-\t%s\n", password, errs[i])
+				continue
+			}
+			fmt.Fprintf(buf, "%s\t%d\n", password, positions[i])
+		}
+		return nil
+	},
+}
+
+var unrankCmd = &cobra.Command{
+	Use:   "unrank [positions...]",
+	Short: "Print guesses at positions (0-based) in generation order, reads stdin if no position is specified",
+	Long:  "Print guesses at positions (0-based) in generation order, reads stdin if no position is specified",
+	RunE: func(cmd *cobra.Command, args []string) error {
+		g, err := manager.LoadGrammar(rulesFolder)
+		if err != nil {
+			return err
+		}
+		pcfg := manager.NewPcfg(g)
+		lines, err := readArgs(args)
+		if err != nil {
+			return err
+		}
+		positions := make([]uint64, len(lines))
+		for i, line := range lines {
+			positions[i], err = strconv.ParseUint(line, 10, 64)
+			if err != nil {
+				return err
+			}
+		}
+		// all positions are found by one walk of pre-terminals
+		guesses, errs := pcfg.UnrankAll(positions)
+		buf := bufio.NewWriter(os.Stdout)
+		defer buf.Flush()
+		for i, position := range positions {
+			if errs[i] != nil {
+				fmt.Fprintf(buf, "%d\t-\t%s\n", position, errs[i])
+				continue
+			}
+			fmt.Fprintf(buf, "%d\t%s\n", position, guesses[i])
+		}
+		return nil
+	},
+}
@@ -2,13 +2,20 @@ package cmd
 
 import (
 	"github.com/dasio/pcfg-manager/manager"
+	"github.com/dasio/pcfg-manager/output"
 	"github.com/spf13/cobra"
+	"os"
+	"os/signal"
+	"syscall"
+	"time"
 )
 
 var (
 	rulesFolder      string
 	grammarFile      string
 	preTerminalsFile string
+	outputSpec       string
+	rotate           uint64
 	inputArgs        manager.InputArgs
 )
 
@@ -16,7 +23,7 @@ var rootCmd = &cobra.Command{
 	Use:   "pcfg-manager",
 	Short: "Password generator",
 	Long:  `Password generator`,
-	RunE: func(cmd *cobra.Command, args []string) error {
+	RunE: func(cmd *cobra.Command, args []string) (err error) {
 		mng := manager.NewManager(rulesFolder)
 		if grammarFile != "" {
 			if err := mng.LoadFromFile(grammarFile); err != nil {
@@ -27,9 +34,30 @@ var rootCmd = &cobra.Command{
 				return err
 			}
 		}
+		out, err := output.Open(outputSpec, rotate)
+		if err != nil {
+			return err
+		}
+		defer func() {
+			if cErr := out.Close(); err == nil {
+				err = cErr
+			}
+		}()
+		mng.Generator.Output = out
 		if preTerminalsFile != "" {
 			return mng.ListTerminals(preTerminalsFile)
 		}
+		if inputArgs.CheckpointFile != "" {
+			sigs := make(chan os.Signal, 1)
+			signal.Notify(sigs, syscall.SIGINT, syscall.SIGTERM)
+			go func() {
+				<-sigs
+				// next signal kills generator immediately
+				signal.Stop(sigs)
+				// generator finishes pending pre-terminals and saves checkpoint
+				mng.Generator.Stop()
+			}()
+		}
 		if err := mng.Start(&inputArgs); err != nil {
 			return err
 		}
@@ -50,9 +78,26 @@ func init() {
 	rootCmd.Flags().StringVarP(&grammarFile, "grammar-file", "", "", "it uses marshaled grammar file instead of parsing")
 	rootCmd.Flags().StringVarP(&preTerminalsFile, "preterminals-file", "", "", "generates terminals from specified preterminals")
 
+	rootCmd.Flags().StringVar(&outputSpec, "output", "-", "where guesses are written: - (stdout), file:path, gzip:path, zstd:path, fifo:path or tcp:host:port")
+	rootCmd.Flags().StringVar(&inputArgs.Format, "format", manager.FormatPlain, "output format: plain, tsv (guess, probability, structure, ordinal) or jsonl")
+	rootCmd.Flags().StringVar(&inputArgs.Dedupe, "dedupe", "", "drop duplicate guesses using exact set or bloom filter (exact, bloom)")
+	rootCmd.Flags().Uint64Var(&inputArgs.DedupeItems, "dedupe-items", 100000000, "expected number of guesses, bloom filter is sized for it")
+	rootCmd.Flags().Float64Var(&inputArgs.DedupeFalsePositive, "dedupe-fp", 0.001, "false positive rate of bloom filter, unique guesses are dropped with this rate")
+	rootCmd.Flags().Uint64Var(&rotate, "rotate", 0, "split output file after every n guesses (0 = no rotation)")
+
 	rootCmd.Flags().UintVarP(&inputArgs.GoRoutines, "go-routines", "g", 1, "how many go routines will be used")
 	rootCmd.Flags().Uint64VarP(&inputArgs.MaxGuesses, "max-guesses", "m", 0, "max guesses before exit (generates at least m terminals, could be more)")
-	rootCmd.Flags().BoolVarP(&inputArgs.Debug, "debug", "d", false, "")
+	rootCmd.Flags().Uint64Var(&inputArgs.Skip, "skip", 0, "skip first n guesses")
+	rootCmd.Flags().Uint64Var(&inputArgs.Limit, "limit", 0, "generate exactly n guesses (after skipped ones)")
+	rootCmd.Flags().DurationVar(&inputArgs.MaxDuration, "max-duration", 0, "stop generation after duration, pending pre-terminals are finished (0 = unlimited)")
+	rootCmd.Flags().BoolVarP(&inputArgs.Debug, "debug", "d", false, "report progress of generation to stderr")
+	rootCmd.Flags().DurationVar(&inputArgs.ProgressInterval, "progress-interval", time.Second*10, "how often is progress reported in debug mode")
+	rootCmd.Flags().Float64Var(&inputArgs.MinProb, "min-prob", 0, "stop generation when probability of pre-terminals drops below this value")
+	rootCmd.Flags().Float64Var(&inputArgs.MaxProb, "max-prob", 0, "skip pre-terminals with probability greater or equal to this value")
+	rootCmd.Flags().IntVar(&inputArgs.MaxQueueSize, "max-queue-size", 0, "maximum size of priority queue, low probability items are dropped and found again later (0 = unlimited)")
+	rootCmd.Flags().StringVar(&inputArgs.CheckpointFile, "checkpoint", "", "file where state of generation is saved periodically and on interrupt")
+	rootCmd.Flags().DurationVar(&inputArgs.CheckpointInterval, "checkpoint-interval", time.Minute*5, "how often is checkpoint saved")
+	rootCmd.Flags().StringVar(&inputArgs.RestoreFile, "restore", "", "continue generation from checkpoint file")
 }
 
 func initConfig() {
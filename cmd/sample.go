@@ -0,0 +1,55 @@
+package cmd
+
+import (
+	"bufio"
+	"errors"
+	"fmt"
+	"github.com/dasio/pcfg-manager/manager"
+	"github.com/spf13/cobra"
+	"math/rand"
+	"os"
+	"time"
+)
+
+var (
+	sampleCount       int
+	sampleSeed        int64
+	sampleProbability bool
+)
+
+func init() {
+	rootCmd.AddCommand(sampleCmd)
+	sampleCmd.Flags().IntVarP(&sampleCount, "count", "n", 10, "how many guesses are sampled")
+	sampleCmd.Flags().Int64Var(&sampleSeed, "seed", 0, "seed of random generator, current time is used if 0")
+	sampleCmd.Flags().BoolVar(&sampleProbability, "probability", false, "print probability of guess")
+}
+
+var sampleCmd = &cobra.Command{
+	Use:   "sample",
+	Short: "Randomly sample guesses from grammar in proportion to their probability",
+	Long:  "Randomly sample guesses from grammar in proportion to their probability",
+	RunE: func(cmd *cobra.Command, args []string) error {
+		g, err := manager.LoadGrammar(rulesFolder)
+		if err != nil {
+			return err
+		}
+		pcfg := manager.NewPcfg(g)
+		if pcfg.StartIndex() == -1 {
+			return errors.New("could not find starting position for the Pcfg")
+		}
+		if sampleSeed == 0 {
+			sampleSeed = time.Now().UnixNano()
+		}
+		sampler := manager.NewSampler(pcfg, rand.New(rand.NewSource(sampleSeed)))
+		buf := bufio.NewWriter(os.Stdout)
+		for i := 0; i < sampleCount; i++ {
+			guess, prob := sampler.Sample()
+			if sampleProbability {
+				fmt.Fprintf(buf, "%s\t%g\n", guess, prob)
+			} else {
+				fmt.Fprintln(buf, guess)
+			}
+		}
+		return buf.Flush()
+	},
+}
@@ -0,0 +1,48 @@
+package cmd
+
+import (
+	"bufio"
+	"fmt"
+	"github.com/dasio/pcfg-manager/manager"
+	"github.com/spf13/cobra"
+	"os"
+	"strings"
+)
+
+func init() {
+	rootCmd.AddCommand(scoreCmd)
+}
+
+var scoreCmd = &cobra.Command{
+	Use:   "score [passwords...]",
+	Short: "Compute probability and parse tree of passwords, reads stdin if no password is specified",
+	Long:  "Compute probability and parse tree of passwords, reads stdin if no password is specified",
+	RunE: func(cmd *cobra.Command, args []string) error {
+		g, err := manager.LoadGrammar(rulesFolder)
+		if err != nil {
+			return err
+		}
+		pcfg := manager.NewPcfg(g)
+		buf := bufio.NewWriter(os.Stdout)
+		defer buf.Flush()
+		score := func(password string) {
+			tree, prob, err := pcfg.Score(password)
+			if err != nil {
+				fmt.Fprintf(buf, "%s\t0\t%s\n", password, err)
+				return
+			}
+			fmt.Fprintf(buf, "%s\t%g\t%s\n", password, prob, pcfg.DescribeTree(tree, password))
+		}
+		if len(args) > 0 {
+			for _, password := range args {
+				score(password)
+			}
+			return nil
+		}
+		scanner := bufio.NewScanner(os.Stdin)
+		for scanner.Scan() {
+			score(strings.TrimSuffix(scanner.Text(), "\r"))
+		}
+		return scanner.Err()
+	},
+}
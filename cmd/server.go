@@ -4,6 +4,9 @@ import (
 	"github.com/dasio/pcfg-manager/manager"
 	"github.com/dasio/pcfg-manager/server"
 	"github.com/spf13/cobra"
+	"os"
+	"os/signal"
+	"syscall"
 	"time"
 )
 
@@ -21,7 +24,15 @@ func init() {
 	serverCmd.Flags().Uint64Var(&serverArgs.ChunkStartSize, "chunk-start-size", 10000, "how many pre-terminals will be sent at connected client")
 	serverCmd.Flags().DurationVar(&serverArgs.ChunkDuration, "chunk-duration", time.Second*30, "how long should each chunk take")
 	serverCmd.Flags().BoolVar(&serverArgs.GenerateTerminals, "generate-terminals", false, "server will generate terminals from preterminals structure and send them")
+	serverCmd.Flags().StringSliceVar(&serverArgs.ExcludePotfiles, "exclude-potfile", nil, "drop guesses cracked in hashcat potfile (can be repeated), guesses are kept as 64-bit hashes, so unique guess is dropped with probability n/2^64 for n excluded guesses")
+	serverCmd.Flags().StringSliceVar(&serverArgs.ExcludeWordlists, "exclude-wordlist", nil, "drop guesses contained in wordlist, e.g. of previous run (can be repeated), guesses are kept as 64-bit hashes, so unique guess is dropped with probability n/2^64 for n excluded guesses")
 	serverCmd.Flags().BoolVar(&serverArgs.SaveStats, "stats", false, "save stats after end")
+	serverCmd.Flags().Float64Var(&serverArgs.MinProb, "min-prob", 0, "stop generation when probability of pre-terminals drops below this value")
+	serverCmd.Flags().Float64Var(&serverArgs.MaxProb, "max-prob", 0, "skip pre-terminals with probability greater or equal to this value")
+	serverCmd.Flags().IntVar(&serverArgs.MaxQueueSize, "max-queue-size", 0, "maximum size of priority queue, low probability items are dropped and found again later (0 = unlimited)")
+	serverCmd.Flags().StringVar(&serverArgs.CheckpointFile, "checkpoint", "", "file where state of generation is saved periodically and on interrupt")
+	serverCmd.Flags().DurationVar(&serverArgs.CheckpointInterval, "checkpoint-interval", time.Minute*5, "how often is checkpoint saved")
+	serverCmd.Flags().StringVar(&serverArgs.RestoreFile, "restore", "", "continue generation from checkpoint file")
 
 }
 
@@ -35,6 +46,17 @@ var serverCmd = &cobra.Command{
 		if err := svc.Load(serverArgs); err != nil {
 			return err
 		}
+		if serverArgs.CheckpointFile != "" {
+			sigs := make(chan os.Signal, 1)
+			signal.Notify(sigs, syscall.SIGINT, syscall.SIGTERM)
+			go func() {
+				<-sigs
+				// next signal kills server immediately
+				signal.Stop(sigs)
+				// server stops generation and saves checkpoint
+				svc.Stop()
+			}()
+		}
 		/*go func() {
 			for {
 				_, _ = bufio.NewReader(os.Stdin).ReadBytes('\n')
@@ -44,6 +66,9 @@ var serverCmd = &cobra.Command{
 		if err := svc.Run(); err != nil {
 			return err
 		}
+		if err := svc.SaveCheckpoint(); err != nil {
+			return err
+		}
 		if serverArgs.SaveStats {
 			if err := svc.SaveStats(); err != nil {
 				return err
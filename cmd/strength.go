@@ -0,0 +1,49 @@
+package cmd
+
+import (
+	"bufio"
+	"fmt"
+	"github.com/dasio/pcfg-manager/manager"
+	"github.com/spf13/cobra"
+	"math/rand"
+	"os"
+	"strings"
+	"time"
+)
+
+var (
+	strengthSamples int
+	strengthSeed    int64
+)
+
+func init() {
+	rootCmd.AddCommand(strengthCmd)
+	strengthCmd.Flags().IntVar(&strengthSamples, "samples", 10000, "how many samples are used for estimation")
+	strengthCmd.Flags().Int64Var(&strengthSeed, "seed", 0, "seed of random generator, current time is used if 0")
+}
+
+var strengthCmd = &cobra.Command{
+	Use:   "strength",
+	Short: "Estimate guess number of passwords read from stdin",
+	Long:  "Estimate how many guesses are generated before each password read from stdin, using Monte Carlo sampling",
+	RunE: func(cmd *cobra.Command, args []string) error {
+		g, err := manager.LoadGrammar(rulesFolder)
+		if err != nil {
+			return err
+		}
+		if strengthSeed == 0 {
+			strengthSeed = time.Now().UnixNano()
+		}
+		pcfg := manager.NewPcfg(g)
+		estimator := manager.NewGuessNumberEstimator(pcfg, strengthSamples, rand.New(rand.NewSource(strengthSeed)))
+		buf := bufio.NewWriter(os.Stdout)
+		defer buf.Flush()
+		scanner := bufio.NewScanner(os.Stdin)
+		for scanner.Scan() {
+			password := strings.TrimSuffix(scanner.Text(), "\r")
+			guessNumber, _ := estimator.EstimatePassword(password)
+			fmt.Fprintf(buf, "%s\t%.0f\n", password, guessNumber)
+		}
+		return scanner.Err()
+	},
+}
@@ -0,0 +1,52 @@
+package cmd
+
+import (
+	"fmt"
+	"github.com/dasio/pcfg-manager/manager"
+	"github.com/sirupsen/logrus"
+	"github.com/spf13/cobra"
+	"io"
+	"os"
+	"path/filepath"
+)
+
+var (
+	trainingFile string
+	trainOutput  string
+	trainForce   bool
+)
+
+func init() {
+	rootCmd.AddCommand(trainCmd)
+	trainCmd.Flags().StringVarP(&trainingFile, "training-file", "t", "", "password list used for training, stdin is used if not specified")
+	trainCmd.Flags().StringVarP(&trainOutput, "output-rules", "o", "", "rules folder where trained grammar is saved")
+	trainCmd.Flags().BoolVar(&trainForce, "force", false, "overwrite grammar which already exists in output rules folder")
+	_ = trainCmd.MarkFlagRequired("output-rules")
+}
+
+var trainCmd = &cobra.Command{
+	Use:   "train",
+	Short: "Train grammar from password list and save it to output rules folder",
+	Long:  "Train grammar from password list and save it to output rules folder, existing grammar is overwritten only with --force",
+	RunE: func(cmd *cobra.Command, args []string) error {
+		// grammar is checked before training, so long training isn't wasted
+		if _, err := os.Stat(filepath.Join(trainOutput, "config.ini")); err == nil && !trainForce {
+			return fmt.Errorf("grammar already exists in %s, use --force to overwrite it", trainOutput)
+		}
+		var input io.Reader = os.Stdin
+		if trainingFile != "" {
+			f, err := os.Open(trainingFile)
+			if err != nil {
+				return err
+			}
+			defer f.Close()
+			input = f
+		}
+		trainer := manager.NewTrainer()
+		if err := trainer.Parse(input); err != nil {
+			return err
+		}
+		logrus.Infof("parsed %d passwords", trainer.Passwords)
+		return trainer.Save(trainOutput)
+	},
+}
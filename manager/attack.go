@@ -0,0 +1,273 @@
+package manager
+
+import (
+	"sort"
+	"strings"
+)
+
+// attack modes of client
+const (
+	AttackPlain = "plain"
+	AttackRules = "rules"
+)
+
+// maxAttackRules is maximum of rules of one pre-terminal, bigger pre-terminals are generated as plaintext
+const maxAttackRules = 100000
+
+// maxRuleFunctions is maximum of functions of one hashcat rule, hashcat skips longer rules as invalid
+const maxRuleFunctions = 31
+
+// RuleAttack is hashcat straight attack with rules, every word of Words is transformed by every rule of Rules
+type RuleAttack struct {
+	Words []string
+	Rules []string
+}
+
+// wordKey identifies replacement of Shadow section, it's the source of words of attack
+type wordKey struct {
+	index      int32
+	transition int32
+}
+
+// ruleSegment is one structure of pre-terminal in order of guess
+type ruleSegment struct {
+	values []string
+	// word is Shadow replacement, values are capitalization masks
+	word *wordKey
+}
+
+// ruleSegments walks pre-terminal like GuessGeneration.Init
+func (p *Pcfg) ruleSegments(section *TreeItem, segments []ruleSegment) ([]ruleSegment, bool) {
+	replacement := p.Grammar.Sections[section.Index].Replacements[section.Transition]
+	switch replacement.Function {
+	case "Transparent":
+		for _, child := range section.Childrens {
+			var ok bool
+			if segments, ok = p.ruleSegments(child, segments); !ok {
+				return nil, false
+			}
+		}
+		return segments, true
+	case "Copy":
+		return append(segments, ruleSegment{values: replacement.Values}), true
+	case "Shadow":
+		if len(section.Childrens) != 1 {
+			return nil, false
+		}
+		capitalization := section.Childrens[0]
+		return append(segments, ruleSegment{
+			values: p.Grammar.Sections[capitalization.Index].Replacements[capitalization.Transition].Values,
+			word:   &wordKey{index: section.Index, transition: section.Transition},
+		}), true
+	}
+	return nil, false
+}
+
+// ruleChar returns true if character can be argument of hashcat rule function
+func ruleChar(ch byte) bool {
+	return ch >= 0x20 && ch < 0x7f
+}
+
+// ruleValues returns true if all values can be written by rules
+func ruleValues(values []string) bool {
+	for _, v := range values {
+		for i := 0; i < len(v); i++ {
+			if !ruleChar(v[i]) {
+				return false
+			}
+		}
+	}
+	return true
+}
+
+// lowercaseWords returns true if all words are lowercase ASCII, so capitalization can be expressed by toggle rules
+func lowercaseWords(words []string) bool {
+	for _, w := range words {
+		for i := 0; i < len(w); i++ {
+			if !ruleChar(w[i]) || ('A' <= w[i] && w[i] <= 'Z') {
+				return false
+			}
+		}
+	}
+	return true
+}
+
+// toggleRule converts capitalization mask to hashcat rule, e.g. ULLU -> T0 T3
+func toggleRule(mask string) (string, bool) {
+	const positions = "0123456789ABCDEFGHIJKLMNOPQRSTUVWXYZ"
+	var rule []string
+	for i := 0; i < len(mask); i++ {
+		if mask[i] != 'U' {
+			continue
+		}
+		if i >= len(positions) {
+			return "", false
+		}
+		rule = append(rule, "T"+positions[i:i+1])
+	}
+	return strings.Join(rule, " "), true
+}
+
+// prependRule returns rule which prepends value, characters are prepended in reverse order
+func prependRule(value string) string {
+	var rule []string
+	for i := len(value) - 1; i >= 0; i-- {
+		rule = append(rule, "^"+value[i:i+1])
+	}
+	return strings.Join(rule, " ")
+}
+
+// appendRule returns rule which appends value
+func appendRule(value string) string {
+	var rule []string
+	for i := 0; i < len(value); i++ {
+		rule = append(rule, "$"+value[i:i+1])
+	}
+	return strings.Join(rule, " ")
+}
+
+// ruleFunctions returns number of functions of the longest rule compiled from segments,
+// it's one toggle for every upper case letter of mask and one function for every prepended or appended character
+func ruleFunctions(segments []ruleSegment, wordPos int) int {
+	functions := 0
+	for i, s := range segments {
+		longest := 0
+		for _, v := range s.values {
+			n := len(v)
+			if i == wordPos {
+				n = strings.Count(v, "U")
+			}
+			if n > longest {
+				longest = n
+			}
+		}
+		functions += longest
+	}
+	return functions
+}
+
+// combineRules returns every combination of one rule from every list
+func combineRules(lists [][]string) []string {
+	rules := []string{""}
+	for _, list := range lists {
+		next := make([]string, 0, len(rules)*len(list))
+		for _, prefix := range rules {
+			for _, r := range list {
+				switch {
+				case prefix == "":
+					next = append(next, r)
+				case r == "":
+					next = append(next, prefix)
+				default:
+					next = append(next, prefix+" "+r)
+				}
+			}
+		}
+		rules = next
+	}
+	return rules
+}
+
+// compileRules converts pre-terminal with exactly one Shadow section and Copy sections to rules applied to words of Shadow section
+func (p *Pcfg) compileRules(preTerminal *TreeItem) (wordKey, []string, bool) {
+	segments, ok := p.ruleSegments(preTerminal, nil)
+	if !ok {
+		return wordKey{}, nil, false
+	}
+	wordPos := -1
+	count := 1
+	for i, s := range segments {
+		if s.word != nil {
+			if wordPos != -1 {
+				return wordKey{}, nil, false
+			}
+			wordPos = i
+		} else if !ruleValues(s.values) {
+			return wordKey{}, nil, false
+		}
+		count *= len(s.values)
+		if count > maxAttackRules {
+			return wordKey{}, nil, false
+		}
+	}
+	if wordPos == -1 {
+		return wordKey{}, nil, false
+	}
+	if ruleFunctions(segments, wordPos) > maxRuleFunctions {
+		return wordKey{}, nil, false
+	}
+	key := *segments[wordPos].word
+	if !lowercaseWords(p.Grammar.Sections[key.index].Replacements[key.transition].Values) {
+		return wordKey{}, nil, false
+	}
+	// toggles are applied first, because positions are relative to word
+	toggles := make([]string, 0, len(segments[wordPos].values))
+	for _, mask := range segments[wordPos].values {
+		rule, ok := toggleRule(mask)
+		if !ok {
+			return wordKey{}, nil, false
+		}
+		toggles = append(toggles, rule)
+	}
+	lists := [][]string{toggles}
+	// prefix closest to word is prepended first
+	for i := wordPos - 1; i >= 0; i-- {
+		list := make([]string, 0, len(segments[i].values))
+		for _, v := range segments[i].values {
+			list = append(list, prependRule(v))
+		}
+		lists = append(lists, list)
+	}
+	for i := wordPos + 1; i < len(segments); i++ {
+		list := make([]string, 0, len(segments[i].values))
+		for _, v := range segments[i].values {
+			list = append(list, appendRule(v))
+		}
+		lists = append(lists, list)
+	}
+	rules := combineRules(lists)
+	for i, r := range rules {
+		if r == "" {
+			// hashcat rule which doesn't change word
+			rules[i] = ":"
+		}
+	}
+	return key, rules, true
+}
+
+// CompileAttacks converts pre-terminals to hashcat rule attacks, pre-terminals with the same words are merged to one attack.
+// Pre-terminals which can't be expressed by rules are returned, so they can be generated as plaintext.
+func (p *Pcfg) CompileAttacks(preTerminals []*TreeItem) ([]*RuleAttack, []*TreeItem) {
+	var plain []*TreeItem
+	var keys []wordKey
+	rules := make(map[wordKey]map[string]struct{})
+	for _, preTerminal := range preTerminals {
+		key, compiled, ok := p.compileRules(preTerminal)
+		if !ok {
+			plain = append(plain, preTerminal)
+			continue
+		}
+		set, ok := rules[key]
+		if !ok {
+			set = make(map[string]struct{})
+			rules[key] = set
+			keys = append(keys, key)
+		}
+		for _, r := range compiled {
+			set[r] = struct{}{}
+		}
+	}
+	attacks := make([]*RuleAttack, 0, len(keys))
+	for _, key := range keys {
+		attack := &RuleAttack{
+			Words: p.Grammar.Sections[key.index].Replacements[key.transition].Values,
+			Rules: make([]string, 0, len(rules[key])),
+		}
+		for r := range rules[key] {
+			attack.Rules = append(attack.Rules, r)
+		}
+		sort.Strings(attack.Rules)
+		attacks = append(attacks, attack)
+	}
+	return attacks, plain
+}
@@ -0,0 +1,151 @@
+package manager
+
+import (
+	"reflect"
+	"sort"
+	"strings"
+	"testing"
+)
+
+// parseTree returns the only derivation of password in grammar
+func parseTree(t *testing.T, p *Pcfg, password string) *TreeItem {
+	t.Helper()
+	trees := p.Parse(password)
+	if len(trees) != 1 {
+		t.Fatalf("Parse(%q) returned %d derivations, want 1", password, len(trees))
+	}
+	return trees[0]
+}
+
+// applyRule applies hashcat rule with functions used by compileRules to word
+func applyRule(t *testing.T, word, rule string) string {
+	t.Helper()
+	const positions = "0123456789ABCDEFGHIJKLMNOPQRSTUVWXYZ"
+	guess := []byte(word)
+	for i := 0; i < len(rule); i++ {
+		switch rule[i] {
+		case ' ', ':':
+		case 'T':
+			i++
+			pos := strings.IndexByte(positions, rule[i])
+			if 'a' <= guess[pos] && guess[pos] <= 'z' {
+				guess[pos] = guess[pos] - 'a' + 'A'
+			} else if 'A' <= guess[pos] && guess[pos] <= 'Z' {
+				guess[pos] = guess[pos] - 'A' + 'a'
+			}
+		case '^':
+			i++
+			guess = append([]byte{rule[i]}, guess...)
+		case '$':
+			i++
+			guess = append(guess, rule[i])
+		default:
+			t.Fatalf("unknown function %q of rule %q", rule[i], rule)
+		}
+	}
+	return string(guess)
+}
+
+func TestCompileRules(t *testing.T) {
+	p, _ := loadTiny(t)
+	tests := []struct {
+		password string
+		words    []string
+		rules    []string
+	}{
+		{"pass", []string{"pass"}, []string{":"}},
+		{"pass12", []string{"pass"}, []string{"$1 $2"}},
+		// UUUU and LLLU have the same probability, so they are one replacement
+		{"FISH12", []string{"cats", "dogs", "fish"}, []string{"T0 T1 T2 T3 $1 $2", "T3 $1 $2"}},
+		{"LOVE11", []string{"love", "blue"}, []string{
+			"T0 T1 T2 T3 $1 $1", "T0 T1 T2 T3 $9 $9", "T0 T1 T2 T3 $6 $9", "T3 $1 $1", "T3 $9 $9", "T3 $6 $9",
+		}},
+		// toggles are first, prefix closest to word is prepended first
+		{"12!Sun", []string{"dog", "sun"}, []string{"T0 ^! ^2 ^1"}},
+		{"Cat1", []string{"cat"}, []string{"T0 $1"}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.password, func(t *testing.T) {
+			tree := parseTree(t, p, tt.password)
+			key, rules, ok := p.compileRules(tree)
+			if !ok {
+				t.Fatal("pre-terminal wasn't compiled to rules")
+			}
+			words := p.Grammar.Sections[key.index].Replacements[key.transition].Values
+			if !reflect.DeepEqual(words, tt.words) || !reflect.DeepEqual(rules, tt.rules) {
+				t.Errorf("compileRules() = %q, %q, want %q, %q", words, rules, tt.words, tt.rules)
+			}
+		})
+	}
+}
+
+func TestCompileAttacks(t *testing.T) {
+	p, preTerminals := loadTiny(t)
+	attacks, plain := p.CompileAttacks(preTerminals)
+	// words and rules generate the same guesses as pre-terminals
+	var want, got []string
+	for _, item := range preTerminals {
+		if _, _, ok := p.compileRules(item); !ok {
+			continue
+		}
+		gen := NewGuessGeneration(p.Grammar, item)
+		for guess := gen.First(); guess != ""; guess = gen.Next() {
+			want = append(want, guess)
+		}
+	}
+	for _, attack := range attacks {
+		for _, word := range attack.Words {
+			for _, rule := range attack.Rules {
+				got = append(got, applyRule(t, word, rule))
+			}
+		}
+	}
+	sort.Strings(want)
+	sort.Strings(got)
+	if len(want) == 0 || !reflect.DeepEqual(got, want) {
+		t.Errorf("rule attacks generate %d guesses, pre-terminals %d", len(got), len(want))
+	}
+	// only Markov pre-terminals can't be expressed by rules in fixture grammar
+	if len(plain) == 0 {
+		t.Error("Markov pre-terminals weren't returned as plaintext")
+	}
+	for _, item := range plain {
+		if !hasMarkov(p.Grammar, item) {
+			t.Errorf("pre-terminal %s wasn't compiled to rules", debugItem(item))
+		}
+	}
+}
+
+func TestCompileRulesPlaintext(t *testing.T) {
+	tests := []struct {
+		name     string
+		password string
+		// digits replaces values of digits of pre-terminal
+		digits string
+		ok     bool
+	}{
+		{name: "Markov", password: "ab"},
+		{name: "31 functions", password: "FISH12", digits: strings.Repeat("1", 27), ok: true},
+		{name: "32 functions", password: "FISH12", digits: strings.Repeat("1", 28)},
+		// space is argument of function, it isn't separator of functions
+		{name: "space", password: "pass12", digits: strings.Repeat(" ", 31), ok: true},
+		{name: "character which isn't printable", password: "pass12", digits: "1\t"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			p, _ := loadTiny(t)
+			tree := parseTree(t, p, tt.password)
+			if tt.digits != "" {
+				digits := tree.Childrens[len(tree.Childrens)-1]
+				p.Grammar.Sections[digits.Index].Replacements[digits.Transition].Values = []string{tt.digits}
+			}
+			if _, _, ok := p.compileRules(tree); ok != tt.ok {
+				t.Errorf("compileRules() = %t, want %t", ok, tt.ok)
+			}
+			attacks, plain := p.CompileAttacks([]*TreeItem{tree})
+			if tt.ok && (len(attacks) != 1 || len(plain) != 0) || !tt.ok && (len(attacks) != 0 || len(plain) != 1) {
+				t.Errorf("CompileAttacks() returned %d attacks and %d plaintext pre-terminals", len(attacks), len(plain))
+			}
+		})
+	}
+}
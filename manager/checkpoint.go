@@ -0,0 +1,92 @@
+package manager
+
+import (
+	pb "github.com/dasio/pcfg-manager/proto"
+	"github.com/golang/protobuf/proto"
+	"io/ioutil"
+	"os"
+	"sort"
+	"sync/atomic"
+)
+
+// Checkpoint returns state of generation, pending pre-terminals are generated again after restore
+func (g *Generator) Checkpoint() *pb.Checkpoint {
+	g.mu.Lock()
+	defer g.mu.Unlock()
+	ids := make([]uint64, 0, len(g.pending))
+	for id := range g.pending {
+		ids = append(ids, id)
+	}
+	sort.Slice(ids, func(i, j int) bool {
+		return ids[i] < ids[j]
+	})
+	generated := atomic.LoadUint64(&g.Generated)
+	covered := g.covered
+	pending := make([]*pb.TreeItem, 0, len(ids)+len(g.restored))
+	for _, id := range ids {
+		item := g.pending[id]
+		generated -= item.Count
+		covered -= g.Pcfg.FindProbability(item.Item) * float64(item.Count)
+		pending = append(pending, TreeItemToProto(item.Item))
+	}
+	for _, tree := range g.restored {
+		pending = append(pending, TreeItemToProto(tree))
+	}
+	items := g.pQue.Items()
+	queue := make([]*pb.QueueItem, 0, len(items))
+	for _, item := range items {
+		queue = append(queue, QueueItemToProto(item))
+	}
+	return &pb.Checkpoint{
+		Queue:     queue,
+		Pending:   pending,
+		MaxProb:   g.pQue.MaxProb(),
+		MinProb:   g.pQue.MinProb(),
+		Generated: generated,
+		Covered:   covered,
+	}
+}
+
+// SaveCheckpoint writes checkpoint to file, file is replaced only after whole checkpoint was written
+func (g *Generator) SaveCheckpoint(file string) error {
+	b, err := proto.Marshal(g.Checkpoint())
+	if err != nil {
+		return err
+	}
+	tmpFile := file + ".tmp"
+	if err := ioutil.WriteFile(tmpFile, b, 0644); err != nil {
+		return err
+	}
+	return os.Rename(tmpFile, file)
+}
+
+// Restore continues generation from checkpoint, it has to be called before Run
+func (g *Generator) Restore(checkpoint *pb.Checkpoint) {
+	g.mu.Lock()
+	defer g.mu.Unlock()
+	items := make([]*QueueItem, 0, len(checkpoint.Queue))
+	for _, item := range checkpoint.Queue {
+		items = append(items, QueueItemFromProto(item))
+	}
+	g.pQue.Restore(items, checkpoint.MaxProb, checkpoint.MinProb)
+	g.restored = g.restored[:0]
+	for _, tree := range checkpoint.Pending {
+		g.restored = append(g.restored, TreeItemFromProto(tree))
+	}
+	g.pending = make(map[uint64]PreTerminalItem)
+	atomic.StoreUint64(&g.Generated, checkpoint.Generated)
+	g.covered = checkpoint.Covered
+}
+
+func (g *Generator) LoadCheckpoint(file string) error {
+	b, err := ioutil.ReadFile(file)
+	if err != nil {
+		return err
+	}
+	var checkpoint pb.Checkpoint
+	if err := proto.Unmarshal(b, &checkpoint); err != nil {
+		return err
+	}
+	g.Restore(&checkpoint)
+	return nil
+}
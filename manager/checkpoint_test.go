@@ -0,0 +1,90 @@
+package manager
+
+import (
+	"bytes"
+	"errors"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+var errWriteFailed = errors.New("write failed")
+
+// failingWriter accepts first limit bytes, next writes fail
+type failingWriter struct {
+	buf   bytes.Buffer
+	limit int
+}
+
+func (w *failingWriter) Write(p []byte) (int, error) {
+	if w.buf.Len()+len(p) > w.limit {
+		n := w.limit - w.buf.Len()
+		w.buf.Write(p[:n])
+		return n, errWriteFailed
+	}
+	return w.buf.Write(p)
+}
+
+// lines returns complete lines of output
+func lines(out []byte) map[string]bool {
+	res := make(map[string]bool)
+	for _, line := range strings.SplitAfter(string(out), "\n") {
+		if strings.HasSuffix(line, "\n") {
+			res[strings.TrimSuffix(line, "\n")] = true
+		}
+	}
+	return res
+}
+
+func TestCheckpointRoundTrip(t *testing.T) {
+	p, _ := loadTiny(t)
+	var full bytes.Buffer
+	if _, err := generate(t, p, "", &InputArgs{GoRoutines: 4}, &full); err != nil {
+		t.Fatal(err)
+	}
+	tests := []struct {
+		name string
+		args InputArgs
+		// limit of output, 0 means output doesn't fail
+		limit int
+	}{
+		{name: "max guesses", args: InputArgs{GoRoutines: 4, MaxGuesses: 100}},
+		{name: "failed write", args: InputArgs{GoRoutines: 4}, limit: 600},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			file := filepath.Join(t.TempDir(), "checkpoint")
+			first := &failingWriter{limit: tt.limit}
+			if tt.limit == 0 {
+				first.limit = full.Len() * 2
+			}
+			g, err := generate(t, p, "", &tt.args, first)
+			if tt.limit > 0 && err != errWriteFailed {
+				t.Fatalf("Run() = %v, want %v", err, errWriteFailed)
+			}
+			if tt.limit == 0 && err != nil {
+				t.Fatal(err)
+			}
+			if err := g.SaveCheckpoint(file); err != nil {
+				t.Fatal(err)
+			}
+			var rest bytes.Buffer
+			if _, err := generate(t, p, file, &InputArgs{GoRoutines: 4}, &rest); err != nil {
+				t.Fatal(err)
+			}
+			if tt.limit == 0 && rest.Len() >= full.Len() {
+				t.Errorf("restored generation wrote %d bytes, whole generation %d", rest.Len(), full.Len())
+			}
+			// pending pre-terminals are generated again, but no guess can be skipped
+			written := lines(first.buf.Bytes())
+			for guess := range lines(rest.Bytes()) {
+				written[guess] = true
+			}
+			for guess := range lines(full.Bytes()) {
+				if !written[guess] {
+					t.Errorf("guess %q is missing after restore", guess)
+				}
+			}
+		})
+	}
+}
@@ -0,0 +1,197 @@
+package manager
+
+import (
+	"bytes"
+	"io"
+	"math"
+)
+
+// dedupe modes
+const (
+	DedupeNone  = ""
+	DedupeExact = "exact"
+	DedupeBloom = "bloom"
+)
+
+// GuessSet is set of guesses, Add returns false if guess was already in set
+type GuessSet interface {
+	Add(guess []byte) bool
+}
+
+type exactSet map[string]struct{}
+
+// NewExactSet returns set which stores every guess, it never drops unique guess, but it needs memory for all of them
+func NewExactSet() GuessSet {
+	return exactSet{}
+}
+
+func (s exactSet) Add(guess []byte) bool {
+	if _, ok := s[string(guess)]; ok {
+		return false
+	}
+	s[string(guess)] = struct{}{}
+	return true
+}
+
+// BloomFilter is probabilistic set with fixed size, unique guess is dropped with false positive rate
+type BloomFilter struct {
+	bits   []uint64
+	size   uint64
+	hashes uint64
+}
+
+// NewBloomFilter returns filter sized for items with false positive rate fpRate
+func NewBloomFilter(items uint64, fpRate float64) *BloomFilter {
+	if items == 0 {
+		items = 1
+	}
+	if fpRate <= 0 || fpRate >= 1 {
+		fpRate = 0.001
+	}
+	size := uint64(math.Ceil(-float64(items) * math.Log(fpRate) / (math.Ln2 * math.Ln2)))
+	if size < 64 {
+		size = 64
+	}
+	hashes := uint64(math.Round(float64(size) / float64(items) * math.Ln2))
+	if hashes < 1 {
+		hashes = 1
+	}
+	return &BloomFilter{
+		bits:   make([]uint64, (size+63)/64),
+		size:   size,
+		hashes: hashes,
+	}
+}
+
+// hash64 returns FNV-1a hash of guess
+func hash64(guess []byte) uint64 {
+	h := uint64(14695981039346656037)
+	for _, b := range guess {
+		h ^= uint64(b)
+		h *= 1099511628211
+	}
+	return h
+}
+
+// bloomHash returns two hashes of guess (FNV-1a and its mix), other hashes are combination of them
+func bloomHash(guess []byte) (uint64, uint64) {
+	h := hash64(guess)
+	h2 := h
+	h2 ^= h2 >> 33
+	h2 *= 0xff51afd7ed558ccd
+	h2 ^= h2 >> 33
+	h2 *= 0xc4ceb9fe1a85ec53
+	h2 ^= h2 >> 33
+	return h, h2 | 1
+}
+
+func (f *BloomFilter) Add(guess []byte) bool {
+	h1, h2 := bloomHash(guess)
+	added := false
+	for i := uint64(0); i < f.hashes; i++ {
+		bit := (h1 + i*h2) % f.size
+		mask := uint64(1) << (bit % 64)
+		if f.bits[bit/64]&mask == 0 {
+			f.bits[bit/64] |= mask
+			added = true
+		}
+	}
+	return added
+}
+
+// Contains returns true if guess is probably in filter
+func (f *BloomFilter) Contains(guess []byte) bool {
+	h1, h2 := bloomHash(guess)
+	for i := uint64(0); i < f.hashes; i++ {
+		bit := (h1 + i*h2) % f.size
+		if f.bits[bit/64]&(uint64(1)<<(bit%64)) == 0 {
+			return false
+		}
+	}
+	return true
+}
+
+// Deduper drops guesses which were already seen, it isn't safe for concurrent use
+type Deduper struct {
+	set     GuessSet
+	dropped uint64
+}
+
+// NewDeduper returns deduper for mode, items and fpRate are used for sizing of bloom filter, nil is returned for DedupeNone
+func NewDeduper(mode string, items uint64, fpRate float64) (*Deduper, error) {
+	switch mode {
+	case DedupeNone:
+		return nil, nil
+	case DedupeExact:
+		return &Deduper{set: NewExactSet()}, nil
+	case DedupeBloom:
+		return &Deduper{set: NewBloomFilter(items, fpRate)}, nil
+	}
+	return nil, ErrUnknownDedupe
+}
+
+// Keep returns true if guess wasn't seen yet
+func (d *Deduper) Keep(guess []byte) bool {
+	if d.set.Add(guess) {
+		return true
+	}
+	d.dropped++
+	return false
+}
+
+// Dropped returns how many guesses were dropped
+func (d *Deduper) Dropped() uint64 {
+	return d.dropped
+}
+
+type filterWriter struct {
+	w       io.Writer
+	keep    func(guess []byte) bool
+	partial []byte
+	out     []byte
+}
+
+// NewFilterWriter returns writer which passes newline separated guesses to w only if keep returns true,
+// last guess without newline is written after Close
+func NewFilterWriter(w io.Writer, keep func(guess []byte) bool) io.WriteCloser {
+	return &filterWriter{w: w, keep: keep}
+}
+
+func (w *filterWriter) Write(p []byte) (int, error) {
+	w.out = w.out[:0]
+	n := len(p)
+	for len(p) > 0 {
+		i := bytes.IndexByte(p, '\n')
+		if i == -1 {
+			w.partial = append(w.partial, p...)
+			break
+		}
+		line := p[:i]
+		if len(w.partial) > 0 {
+			w.partial = append(w.partial, line...)
+			line = w.partial
+		}
+		if w.keep(line) {
+			w.out = append(w.out, line...)
+			w.out = append(w.out, '\n')
+		}
+		w.partial = w.partial[:0]
+		p = p[i+1:]
+	}
+	if len(w.out) == 0 {
+		return n, nil
+	}
+	if _, err := w.w.Write(w.out); err != nil {
+		return 0, err
+	}
+	return n, nil
+}
+
+func (w *filterWriter) Close() error {
+	if len(w.partial) == 0 || !w.keep(w.partial) {
+		return nil
+	}
+	_, err := w.w.Write(append(w.partial, '\n'))
+	w.partial = w.partial[:0]
+	return err
+}
@@ -0,0 +1,105 @@
+package manager
+
+import (
+	"bytes"
+	"fmt"
+	"testing"
+)
+
+func TestExactSet(t *testing.T) {
+	s := NewExactSet()
+	tests := []struct {
+		guess string
+		added bool
+	}{
+		{"pass", true},
+		{"love", true},
+		{"pass", false},
+		{"Pass", true},
+		{"pass ", true},
+		{"", true},
+		{"", false},
+		{"love", false},
+	}
+	for _, tt := range tests {
+		if added := s.Add([]byte(tt.guess)); added != tt.added {
+			t.Errorf("Add(%q) = %t, want %t", tt.guess, added, tt.added)
+		}
+	}
+}
+
+func TestBloomFilter(t *testing.T) {
+	const items = 10000
+	f := NewBloomFilter(items, 0.01)
+	for i := 0; i < items; i++ {
+		f.Add([]byte(fmt.Sprintf("guess%d", i)))
+	}
+	for i := 0; i < items; i++ {
+		guess := []byte(fmt.Sprintf("guess%d", i))
+		if !f.Contains(guess) || f.Add(guess) {
+			t.Fatalf("guess %q which was added isn't in filter", guess)
+		}
+	}
+	falsePositives := 0
+	for i := 0; i < items; i++ {
+		if f.Contains([]byte(fmt.Sprintf("unseen%d", i))) {
+			falsePositives++
+		}
+	}
+	if falsePositives > items*3/100 {
+		t.Errorf("%d false positives of %d guesses, false positive rate is 0.01", falsePositives, items)
+	}
+}
+
+func TestDeduper(t *testing.T) {
+	p, preTerminals := loadTiny(t)
+	var once bytes.Buffer
+	if err := p.ListTerminalsParallel(preTerminals, &once, 4, nil); err != nil {
+		t.Fatal(err)
+	}
+	guesses := uint64(bytes.Count(once.Bytes(), []byte("\n")))
+	// every pre-terminal is generated twice, so every guess has duplicate
+	twice := append(append([]*TreeItem{}, preTerminals...), preTerminals...)
+	for _, mode := range []string{DedupeExact, DedupeBloom} {
+		t.Run(mode, func(t *testing.T) {
+			d, err := NewDeduper(mode, guesses, 0.0001)
+			if err != nil {
+				t.Fatal(err)
+			}
+			var out bytes.Buffer
+			if err := p.ListTerminalsParallel(twice, &out, 4, d); err != nil {
+				t.Fatal(err)
+			}
+			if !bytes.Equal(out.Bytes(), once.Bytes()) {
+				t.Errorf("deduplicated output differs from output of unique guesses")
+			}
+			if d.Dropped() != guesses {
+				t.Errorf("Dropped() = %d, want %d", d.Dropped(), guesses)
+			}
+		})
+	}
+	if _, err := NewDeduper("sorted", 0, 0); err != ErrUnknownDedupe {
+		t.Errorf("NewDeduper of unknown mode = %v, want %v", err, ErrUnknownDedupe)
+	}
+}
+
+func TestFilterWriter(t *testing.T) {
+	var out bytes.Buffer
+	d, _ := NewDeduper(DedupeExact, 0, 0)
+	w := NewFilterWriter(&out, d.Keep)
+	// guesses are split between writes and last guess doesn't end with newline
+	for _, chunk := range []string{"pass\nlo", "ve\npass\n", "love\nab"} {
+		if _, err := w.Write([]byte(chunk)); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if err := w.Close(); err != nil {
+		t.Fatal(err)
+	}
+	if want := "pass\nlove\nab\n"; out.String() != want {
+		t.Errorf("output is %q, want %q", out.String(), want)
+	}
+	if d.Dropped() != 2 {
+		t.Errorf("Dropped() = %d, want 2", d.Dropped())
+	}
+}
@@ -9,4 +9,9 @@ var (
 	ErrGrammarMapping       = errors.New("invalid keys to Grammar mapping")
 	ErrParsingBaseStructure = errors.New("errors while parsing base structure")
 	ErrPriorirtyQueEmpty    = errors.New("priority queue is empty")
+	ErrOmenLevel            = errors.New("failed to parse omen level")
+	ErrNoDerivation         = errors.New("password can't be generated by grammar")
+	ErrMissingOmen          = errors.New("grammar contains Markov section without omen grammar")
+	ErrUnknownFormat        = errors.New("unknown output format, expected plain, tsv or jsonl")
+	ErrUnknownDedupe        = errors.New("unknown dedupe mode, expected exact or bloom")
 )
@@ -0,0 +1,162 @@
+package manager
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/hex"
+	"io"
+	"os"
+	"sort"
+	"strings"
+	"sync/atomic"
+)
+
+// Exclusion is set of guesses which were already tried, e.g. cracked passwords from potfile or wordlist of previous run.
+// Guesses are stored as sorted 64-bit hashes, so unique guess is excluded only in case of hash collision.
+type Exclusion struct {
+	hashes   []uint64
+	excluded uint64
+}
+
+// maxLineSize is the longest line of potfile or wordlist
+const maxLineSize = 1024 * 1024
+
+// LoadExclusion loads guesses from potfiles and wordlists, hashes of hash list are used to split salted lines of potfile
+func LoadExclusion(potfiles, wordlists, hashes []string) (*Exclusion, error) {
+	e := &Exclusion{}
+	known := make(map[string]bool, len(hashes))
+	for _, h := range hashes {
+		known[strings.ToLower(strings.TrimSpace(h))] = true
+	}
+	loadPotfile := func(r io.Reader) error {
+		return e.LoadPotfile(r, func(hash string) bool {
+			return known[strings.ToLower(hash)]
+		})
+	}
+	for _, file := range potfiles {
+		if err := e.loadFile(file, loadPotfile); err != nil {
+			return nil, err
+		}
+	}
+	for _, file := range wordlists {
+		if err := e.loadFile(file, e.LoadWordlist); err != nil {
+			return nil, err
+		}
+	}
+	return e, nil
+}
+
+func (e *Exclusion) loadFile(file string, load func(r io.Reader) error) error {
+	f, err := os.Open(file)
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+	return load(f)
+}
+
+// LoadPotfile adds passwords of potfile, lines are split by SplitHashLine
+func (e *Exclusion) LoadPotfile(r io.Reader, known func(hash string) bool) error {
+	return e.load(r, func(line []byte) []byte {
+		_, plain, ok := SplitHashLine(string(line), known)
+		if !ok {
+			return nil
+		}
+		return []byte(plain)
+	})
+}
+
+// LoadWordlist adds every line of wordlist
+func (e *Exclusion) LoadWordlist(r io.Reader) error {
+	return e.load(r, func(line []byte) []byte {
+		return line
+	})
+}
+
+func (e *Exclusion) load(r io.Reader, guess func(line []byte) []byte) error {
+	scanner := bufio.NewScanner(r)
+	scanner.Buffer(make([]byte, 0, 64*1024), maxLineSize)
+	for scanner.Scan() {
+		line := bytes.TrimSuffix(scanner.Bytes(), []byte("\r"))
+		if g := guess(line); g != nil {
+			e.hashes = append(e.hashes, hash64(g))
+		}
+	}
+	if err := scanner.Err(); err != nil {
+		return err
+	}
+	sort.Slice(e.hashes, func(i, j int) bool {
+		return e.hashes[i] < e.hashes[j]
+	})
+	// duplicates are removed, so sorted slice is as small as possible
+	unique := e.hashes[:0]
+	for i, h := range e.hashes {
+		if i == 0 || h != e.hashes[i-1] {
+			unique = append(unique, h)
+		}
+	}
+	e.hashes = unique
+	return nil
+}
+
+// SplitHashLine splits line of hashcat potfile or outfile in hash[:salt]:plain format and decodes password.
+// Line is split at first colon whose prefix is known hash, salt and password can contain colons.
+// If no prefix is known or known is nil, line is split at first colon, which is right for unsalted hashes.
+func SplitHashLine(line string, known func(hash string) bool) (string, string, bool) {
+	first := -1
+	for i := 0; i < len(line); i++ {
+		if line[i] != ':' {
+			continue
+		}
+		if first == -1 {
+			first = i
+		}
+		if known != nil && known(line[:i]) {
+			return line[:i], string(DecodeHexPlain([]byte(line[i+1:]))), true
+		}
+	}
+	if first == -1 {
+		return "", "", false
+	}
+	return line[:first], string(DecodeHexPlain([]byte(line[first+1:]))), true
+}
+
+// DecodeHexPlain decodes password in hashcat format $HEX[...], other passwords are returned unchanged
+func DecodeHexPlain(plain []byte) []byte {
+	if !bytes.HasPrefix(plain, []byte("$HEX[")) || !bytes.HasSuffix(plain, []byte("]")) {
+		return plain
+	}
+	decoded := make([]byte, hex.DecodedLen(len(plain)-6))
+	if _, err := hex.Decode(decoded, plain[5:len(plain)-1]); err != nil {
+		return plain
+	}
+	return decoded
+}
+
+// Contains returns true if guess was loaded
+func (e *Exclusion) Contains(guess []byte) bool {
+	h := hash64(guess)
+	i := sort.Search(len(e.hashes), func(i int) bool {
+		return e.hashes[i] >= h
+	})
+	return i < len(e.hashes) && e.hashes[i] == h
+}
+
+// Keep returns true if guess wasn't loaded, excluded guesses are counted
+func (e *Exclusion) Keep(guess []byte) bool {
+	if e.Contains(guess) {
+		atomic.AddUint64(&e.excluded, 1)
+		return false
+	}
+	return true
+}
+
+// Excluded returns how many guesses were dropped by Keep
+func (e *Exclusion) Excluded() uint64 {
+	return atomic.LoadUint64(&e.excluded)
+}
+
+// Len returns number of unique loaded guesses
+func (e *Exclusion) Len() int {
+	return len(e.hashes)
+}
@@ -0,0 +1,125 @@
+package manager
+
+import (
+	"io/ioutil"
+	"path/filepath"
+	"testing"
+)
+
+// known returns function which reports hashes of hash list
+func known(hashes ...string) func(hash string) bool {
+	return func(hash string) bool {
+		for _, h := range hashes {
+			if h == hash {
+				return true
+			}
+		}
+		return false
+	}
+}
+
+func TestSplitHashLine(t *testing.T) {
+	const md5 = "5f4dcc3b5aa765d61d8327deb882cf99"
+	tests := []struct {
+		name  string
+		line  string
+		known func(hash string) bool
+		hash  string
+		plain string
+		ok    bool
+	}{
+		{"unsalted", md5 + ":password", nil, md5, "password", true},
+		{"colon in plaintext", md5 + ":pass:word", nil, md5, "pass:word", true},
+		{"known unsalted with colon in plaintext", md5 + ":pass:word", known(md5), md5, "pass:word", true},
+		{"salted", md5 + ":salt:password", known(md5 + ":salt"), md5 + ":salt", "password", true},
+		{"colon in salt and plaintext", md5 + ":sa:lt:pass:word", known(md5 + ":sa:lt"), md5 + ":sa:lt", "pass:word", true},
+		{"unknown salted hash", md5 + ":salt:password", known("other"), md5, "salt:password", true},
+		{"hex plaintext", md5 + ":$HEX[70613a7373]", nil, md5, "pa:ss", true},
+		{"salted hex plaintext", md5 + ":salt:$HEX[70617373]", known(md5 + ":salt"), md5 + ":salt", "pass", true},
+		{"empty plaintext", md5 + ":", nil, md5, "", true},
+		{"without colon", "password", nil, "", "", false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			hash, plain, ok := SplitHashLine(tt.line, tt.known)
+			if hash != tt.hash || plain != tt.plain || ok != tt.ok {
+				t.Errorf("SplitHashLine(%q) = %q, %q, %t, want %q, %q, %t", tt.line, hash, plain, ok, tt.hash, tt.plain, tt.ok)
+			}
+		})
+	}
+}
+
+func TestDecodeHexPlain(t *testing.T) {
+	tests := []struct {
+		plain string
+		want  string
+	}{
+		{"password", "password"},
+		{"$HEX[70617373]", "pass"},
+		{"$HEX[636166c3a9]", "café"},
+		{"$HEX[]", ""},
+		// invalid hex is password
+		{"$HEX[7g]", "$HEX[7g]"},
+		{"$HEX[706]", "$HEX[706]"},
+		{"$HEX[70617373", "$HEX[70617373"},
+		{"x$HEX[70617373]", "x$HEX[70617373]"},
+	}
+	for _, tt := range tests {
+		if got := string(DecodeHexPlain([]byte(tt.plain))); got != tt.want {
+			t.Errorf("DecodeHexPlain(%q) = %q, want %q", tt.plain, got, tt.want)
+		}
+	}
+}
+
+func TestExclusion(t *testing.T) {
+	dir := t.TempDir()
+	potfile := filepath.Join(dir, "hashcat.potfile")
+	wordlist := filepath.Join(dir, "wordlist.txt")
+	potfileLines := "5f4dcc3b5aa765d61d8327deb882cf99:password\n" +
+		"b305cadbb3bce54f3aa59c64fec00dea:salt:sal:ted\n" +
+		"8621ffdbc5698829397d97767ac13db3:$HEX[6c6f76653a31]\r\n" +
+		"invalid line\n"
+	if err := ioutil.WriteFile(potfile, []byte(potfileLines), 0644); err != nil {
+		t.Fatal(err)
+	}
+	if err := ioutil.WriteFile(wordlist, []byte("pass12\r\nFISH12\npass12\n"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	e, err := LoadExclusion([]string{potfile}, []string{wordlist}, []string{"B305CADBB3BCE54F3AA59C64FEC00DEA:salt"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if e.Len() != 5 {
+		t.Errorf("Len() = %d, want 5", e.Len())
+	}
+	tests := []struct {
+		guess string
+		keep  bool
+	}{
+		{"password", false},
+		// salted line is split after known hash
+		{"sal:ted", false},
+		{"salt:sal:ted", true},
+		{"love:1", false},
+		{"$HEX[6c6f76653a31]", true},
+		{"pass12", false},
+		{"FISH12", false},
+		{"fish12", true},
+		{"invalid line", true},
+	}
+	excluded := uint64(0)
+	for _, tt := range tests {
+		if keep := e.Keep([]byte(tt.guess)); keep != tt.keep {
+			t.Errorf("Keep(%q) = %t, want %t", tt.guess, keep, tt.keep)
+		}
+		if !tt.keep {
+			excluded++
+		}
+	}
+	if e.Excluded() != excluded {
+		t.Errorf("Excluded() = %d, want %d", e.Excluded(), excluded)
+	}
+	if _, err := LoadExclusion([]string{filepath.Join(dir, "missing")}, nil, nil); err == nil {
+		t.Error("LoadExclusion of missing potfile didn't return error")
+	}
+}
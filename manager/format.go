@@ -0,0 +1,109 @@
+package manager
+
+import (
+	"bufio"
+	"encoding/json"
+	"io"
+	"strconv"
+)
+
+// output formats of generated guesses
+const (
+	FormatPlain = "plain"
+	// FormatTSV writes guess, probability, base structure and ordinal separated by tabs
+	FormatTSV = "tsv"
+	// FormatJSONL writes one JSON object per guess
+	FormatJSONL = "jsonl"
+)
+
+type annotatedGuess struct {
+	Guess       string  `json:"guess"`
+	Probability float64 `json:"probability"`
+	Structure   string  `json:"structure"`
+	Ordinal     uint64  `json:"ordinal"`
+}
+
+func validFormat(format string) bool {
+	switch format {
+	case "", FormatPlain, FormatTSV, FormatJSONL:
+		return true
+	}
+	return false
+}
+
+// BaseStructure returns base structure of parse tree, e.g. A4D2
+func (p *Pcfg) BaseStructure(tree *TreeItem) string {
+	return p.Grammar.Sections[tree.Index].Replacements[tree.Transition].Values[0]
+}
+
+// annotator writes guesses of one pre-terminal in output format
+type annotator struct {
+	format string
+	buf    *bufio.Writer
+	enc    *json.Encoder
+	item   annotatedGuess
+	prob   string
+}
+
+// newAnnotator returns annotator of pre-terminal, ordinal is number of first guess
+func (p *Pcfg) newAnnotator(preTerminal *TreeItem, buf *bufio.Writer, format string, ordinal uint64) *annotator {
+	a := &annotator{
+		format: format,
+		buf:    buf,
+		item: annotatedGuess{
+			Ordinal: ordinal,
+		},
+	}
+	if format == FormatTSV || format == FormatJSONL {
+		a.item.Probability = p.FindProbability(preTerminal)
+		a.item.Structure = p.BaseStructure(preTerminal)
+		a.prob = strconv.FormatFloat(a.item.Probability, 'g', -1, 64)
+	}
+	if format == FormatJSONL {
+		a.enc = json.NewEncoder(buf)
+		a.enc.SetEscapeHTML(false)
+	}
+	return a
+}
+
+func (a *annotator) write(guess []byte) error {
+	var err error
+	switch a.format {
+	case FormatJSONL:
+		a.item.Guess = string(guess)
+		err = a.enc.Encode(&a.item)
+	case FormatTSV:
+		_, err = a.buf.WriteString(string(guess) + "\t" + a.prob + "\t" + a.item.Structure + "\t" + strconv.FormatUint(a.item.Ordinal, 10) + "\n")
+	default:
+		err = writeLine(a.buf, guess)
+	}
+	a.item.Ordinal++
+	return err
+}
+
+// skip moves ordinal after guess, which isn't written
+func (a *annotator) skip() {
+	a.item.Ordinal++
+}
+
+// ListAnnotatedTerminalsRangeToWriter writes count guesses of pre-terminal starting with guess at offset,
+// every guess is annotated by its probability, base structure and ordinal, which is number of first written guess
+func (p *Pcfg) ListAnnotatedTerminalsRangeToWriter(preTerminal *TreeItem, w io.Writer, format string, offset, count, ordinal uint64) error {
+	if format == "" || format == FormatPlain {
+		return p.ListTerminalsRangeToWriter(preTerminal, w, offset, count)
+	}
+	if count == 0 {
+		return nil
+	}
+	buf := bufferedWriter(w)
+	a := p.newAnnotator(preTerminal, buf, format, ordinal)
+	guessGeneration := NewGuessGeneration(p.Grammar, preTerminal)
+	guess := guessGeneration.SeekBytes(offset)
+	for i := uint64(0); i < count && guess != nil; i++ {
+		if err := a.write(guess); err != nil {
+			return err
+		}
+		guess = guessGeneration.NextBytes()
+	}
+	return buf.Flush()
+}
@@ -1,9 +1,13 @@
 package manager
 
 import (
+	"fmt"
 	"github.com/sirupsen/logrus"
+	"io"
+	"os"
 	"sync"
 	"sync/atomic"
+	"time"
 )
 
 type Generator struct {
@@ -12,6 +16,17 @@ type Generator struct {
 	pQue       *PcfqQueue
 	goRoutines int
 	args       *InputArgs
+	// mu guards queue and pending items
+	mu sync.Mutex
+	// items taken from queue, which weren't processed yet
+	pending  map[uint64]PreTerminalItem
+	nextId   uint64
+	restored []*TreeItem
+	stopped  int32
+	// covered is sum of probabilities of generated guesses
+	covered float64
+	// Output is where Run writes guesses
+	Output io.Writer
 }
 
 func NewGenerator(pcfg *Pcfg) *Generator {
@@ -20,26 +35,124 @@ func NewGenerator(pcfg *Pcfg) *Generator {
 		panic(err)
 	}
 	return &Generator{
-		Pcfg: pcfg,
-		pQue: que,
+		Pcfg:    pcfg,
+		pQue:    que,
+		pending: make(map[uint64]PreTerminalItem),
+		Output:  os.Stdout,
 	}
 }
 
-func (g *Generator) worker(jobs <-chan *TreeItem) {
-	for j := range jobs {
-		guesses, _, _ := g.Pcfg.ListTerminals(j)
-		atomic.AddUint64(&g.Generated, guesses)
+// debugger reports progress of generation to stderr every interval until done is closed
+func (g *Generator) debugger(interval time.Duration, done <-chan struct{}) {
+	if interval <= 0 {
+		interval = time.Second * 10
 	}
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+	lastTime := time.Now()
+	lastGenerated := atomic.LoadUint64(&g.Generated)
+	for {
+		select {
+		case <-ticker.C:
+		case <-done:
+			return
+		}
+		now := time.Now()
+		generated := atomic.LoadUint64(&g.Generated)
+		g.mu.Lock()
+		maxProb := g.pQue.MaxProb()
+		queueSize := g.pQue.Len()
+		covered := g.covered
+		g.mu.Unlock()
+		speed := float64(generated-lastGenerated) / now.Sub(lastTime).Seconds()
+		fmt.Fprintf(os.Stderr, "guesses: %d, speed: %.0f guesses/s, maxProb: %g, covered: %.6f, queue: %d\n",
+			generated, speed, maxProb, covered, queueSize)
+		lastTime, lastGenerated = now, generated
+	}
+}
 
+type PreTerminalItem struct {
+	Id   uint64
+	Item *TreeItem
+	// Position is index of first guess of pre-terminal in generation order
+	Position uint64
+	Count    uint64
 }
 
-func (g *Generator) debugger() {
+// next returns next pre-terminal with probability in [MinProb, MaxProb) window,
+// it stays pending (part of checkpoint) until Complete is called
+func (g *Generator) next(args *InputArgs) (PreTerminalItem, error) {
+	g.mu.Lock()
+	defer g.mu.Unlock()
+	var tree *TreeItem
+	for tree == nil {
+		var prob float64
+		if len(g.restored) > 0 {
+			tree, g.restored = g.restored[0], g.restored[1:]
+			prob = g.Pcfg.FindProbability(tree)
+		} else {
+			item, err := g.pQue.Next()
+			if err != nil {
+				return PreTerminalItem{}, err
+			}
+			tree, prob = item.Tree, item.Probability
+		}
+		if args.MinProb > 0 && prob < args.MinProb {
+			// queue is in descending order, so there is nothing left in window, item is kept for checkpoint
+			g.restored = append([]*TreeItem{tree}, g.restored...)
+			return PreTerminalItem{}, ErrPriorirtyQueEmpty
+		}
+		if args.MaxProb > 0 && prob >= args.MaxProb {
+			tree = nil
+		}
+	}
+	g.nextId++
+	it := PreTerminalItem{
+		Id:    g.nextId,
+		Item:  tree,
+		Count: NewGuessGeneration(g.Pcfg.Grammar, tree).Count(),
+	}
+	g.pending[it.Id] = it
+	g.covered += g.Pcfg.FindProbability(tree) * float64(it.Count)
+	it.Position = atomic.AddUint64(&g.Generated, it.Count) - it.Count
+	return it, nil
+}
 
+// Complete marks pre-terminals as processed
+func (g *Generator) Complete(ids ...uint64) {
+	g.mu.Lock()
+	for _, id := range ids {
+		delete(g.pending, id)
+	}
+	g.mu.Unlock()
 }
 
-type PreTerminalItem struct {
-	Item  *TreeItem
-	Count uint64
+// Stop stops taking new items from queue, Run returns after pending items are processed
+func (g *Generator) Stop() {
+	atomic.StoreInt32(&g.stopped, 1)
+}
+
+func (g *Generator) isStopped() bool {
+	return atomic.LoadInt32(&g.stopped) == 1
+}
+
+// checkpointer periodically saves checkpoint until done is closed
+func (g *Generator) checkpointer(args *InputArgs, done <-chan struct{}) {
+	if args.CheckpointFile == "" || args.CheckpointInterval <= 0 {
+		return
+	}
+	ticker := time.NewTicker(args.CheckpointInterval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ticker.C:
+			if err := g.SaveCheckpoint(args.CheckpointFile); err != nil {
+				logrus.Warn(err)
+			}
+		case <-done:
+			return
+		}
+	}
 }
 
 func (g *Generator) RunForServer(args *InputArgs) <-chan PreTerminalItem {
@@ -49,25 +162,24 @@ func (g *Generator) RunForServer(args *InputArgs) <-chan PreTerminalItem {
 	} else {
 		ch = make(chan PreTerminalItem)
 	}
+	g.pQue.SetMaxSize(args.MaxQueueSize)
+	done := make(chan struct{})
+	go g.checkpointer(args, done)
 	go func() {
-		var err error
-		var item *QueueItem
-		for err != ErrPriorirtyQueEmpty {
+		for !g.isStopped() {
 			if args.MaxGuesses > 0 && atomic.LoadUint64(&g.Generated) >= args.MaxGuesses {
 				break
 			}
-			item, err = g.pQue.Next()
+			item, err := g.next(args)
 			if err != nil {
 				if err != ErrPriorirtyQueEmpty {
 					logrus.Warn(err)
 				}
 				break
 			}
-			guessGeneration := NewGuessGeneration(g.Pcfg.Grammar, item.Tree)
-			count := guessGeneration.Count()
-			atomic.AddUint64(&g.Generated, count)
-			ch <- PreTerminalItem{Item: item.Tree, Count: count}
+			ch <- item
 		}
+		close(done)
 		close(ch)
 	}()
 	return ch
@@ -75,44 +187,85 @@ func (g *Generator) RunForServer(args *InputArgs) <-chan PreTerminalItem {
 
 func (g *Generator) Run(args *InputArgs) error {
 	g.args = args
+	if !validFormat(args.Format) {
+		return ErrUnknownFormat
+	}
+	dedupe, err := NewDeduper(args.Dedupe, args.DedupeItems, args.DedupeFalsePositive)
+	if err != nil {
+		return err
+	}
 
-	var err error
-	var item *QueueItem
-	jobs := make(chan *TreeItem, args.GoRoutines)
+	done := make(chan struct{})
 	wg := sync.WaitGroup{}
-	wg.Add(int(args.GoRoutines))
-
 	if args.Debug {
 		wg.Add(1)
 		go func() {
-			g.debugger()
+			g.debugger(args.ProgressInterval, done)
 			wg.Done()
 		}()
 	}
-	for w := uint(1); w <= args.GoRoutines; w++ {
-		go func() {
-			g.worker(jobs)
-			wg.Done()
-		}()
+	g.pQue.SetMaxSize(args.MaxQueueSize)
+	go g.checkpointer(args, done)
+	p := newPipeline(g.Pcfg, args.GoRoutines, g.Output, args.Format, dedupe, func(j *job) {
+		g.Complete(j.Id)
+	})
+	// guesses in range [Skip, end) are generated
+	end := uint64(0)
+	if args.Limit > 0 {
+		end = args.Skip + args.Limit
 	}
-	for err != ErrPriorirtyQueEmpty {
+	var deadline time.Time
+	if args.MaxDuration > 0 {
+		deadline = time.Now().Add(args.MaxDuration)
+	}
+	for !g.isStopped() {
+		if !deadline.IsZero() && time.Now().After(deadline) {
+			break
+		}
 		if args.MaxGuesses > 0 && atomic.LoadUint64(&g.Generated) >= args.MaxGuesses {
 			break
 		}
-		item, err = g.pQue.Next()
+		if end > 0 && atomic.LoadUint64(&g.Generated) >= end {
+			break
+		}
+		var item PreTerminalItem
+		item, err = g.next(args)
 		if err != nil {
 			if err == ErrPriorirtyQueEmpty {
-				break
+				err = nil
 			}
-			close(jobs)
-			return err
+			break
+		}
+		j := &job{
+			PreTerminalItem: item,
+			count:           item.Count,
+		}
+		if item.Position+item.Count <= args.Skip {
+			g.Complete(item.Id)
+			continue
 		}
-		//guesses, _, _ := g.Pcfg.ListTerminals(item.Tree)
-		//g.Generated += guesses
-		jobs <- item.Tree
+		if item.Position < args.Skip {
+			j.offset = args.Skip - item.Position
+			j.count -= j.offset
+		}
+		if end > 0 && item.Position+item.Count > end {
+			j.count -= item.Position + item.Count - end
+		}
+		p.Add(j)
+	}
+	if pErr := p.Close(); err == nil {
+		err = pErr
 	}
-	close(jobs)
+	close(done)
 	wg.Wait()
-
+	if dedupe != nil {
+		logrus.Infof("dropped %d duplicate guesses", dedupe.Dropped())
+	}
+	if err != nil {
+		return err
+	}
+	if args.CheckpointFile != "" {
+		return g.SaveCheckpoint(args.CheckpointFile)
+	}
 	return nil
 }
@@ -0,0 +1,38 @@
+package manager
+
+import (
+	"bytes"
+	"io"
+	"testing"
+)
+
+// generate runs new generator of fixture grammar, it's restored from checkpoint file if restore isn't empty
+func generate(t *testing.T, p *Pcfg, restore string, args *InputArgs, out io.Writer) (*Generator, error) {
+	t.Helper()
+	g := NewGenerator(p)
+	if restore != "" {
+		if err := g.LoadCheckpoint(restore); err != nil {
+			t.Fatal(err)
+		}
+	}
+	g.Output = out
+	return g, g.Run(args)
+}
+
+func TestRunGoRoutines(t *testing.T) {
+	p, _ := loadTiny(t)
+	for _, format := range []string{FormatPlain, FormatTSV, FormatJSONL} {
+		t.Run(format, func(t *testing.T) {
+			var single, parallel bytes.Buffer
+			if _, err := generate(t, p, "", &InputArgs{GoRoutines: 1, Format: format}, &single); err != nil {
+				t.Fatal(err)
+			}
+			if _, err := generate(t, p, "", &InputArgs{GoRoutines: 8, Format: format}, &parallel); err != nil {
+				t.Fatal(err)
+			}
+			if single.Len() == 0 || !bytes.Equal(single.Bytes(), parallel.Bytes()) {
+				t.Errorf("output of 8 go routines differs from output of 1 go routine")
+			}
+		})
+	}
+}
@@ -29,6 +29,7 @@ type Grammar struct {
 	sectionList []string
 	Sections    []*Section
 	Mapping     GrammarMapping
+	Omen        *OmenGrammar
 }
 
 type ConfigReplacement struct {
@@ -77,6 +78,22 @@ func (g *Grammar) Parse() error {
 	if err := g.Build("START"); err != nil {
 		return err
 	}
+	return g.Validate()
+}
+
+// Validate returns ErrMissingOmen if grammar contains Markov section without omen grammar,
+// grammar loaded from marshaled file or received from server has to be validated before generation
+func (g *Grammar) Validate() error {
+	if g.Omen != nil {
+		return nil
+	}
+	for _, s := range g.Sections {
+		for _, r := range s.Replacements {
+			if r.Function == "Markov" {
+				return ErrMissingOmen
+			}
+		}
+	}
 	return nil
 }
 
@@ -94,7 +111,11 @@ func (g *Grammar) Count() *big.Int {
 				key += s.Name
 			}
 			for _, r := range s.Replacements {
-				nonTerminalsCount[key] += int64(len(r.Values))
+				if r.Function == "Markov" {
+					nonTerminalsCount[key] += int64(g.countMarkov(r))
+				} else {
+					nonTerminalsCount[key] += int64(len(r.Values))
+				}
 			}
 		} else if s.Type == "START" {
 			startSection = s
@@ -117,6 +138,22 @@ func (g *Grammar) Count() *big.Int {
 	}
 	return result
 }
+
+func (g *Grammar) countMarkov(r *Replacement) uint64 {
+	if g.Omen == nil {
+		return 0
+	}
+	count := uint64(0)
+	for _, v := range r.Values {
+		level, err := strconv.ParseInt(v, 10, 32)
+		if err != nil {
+			continue
+		}
+		count += g.Omen.CountLevel(int32(level))
+	}
+	return count
+}
+
 func (g *Grammar) Build(section string) error {
 	for _, s := range g.sectionList {
 		if s == section {
@@ -267,6 +304,12 @@ func (g *Grammar) InsertTerminal(section string) error {
 	}
 	filenames := fromPythonArray(g.cfgFile.Section(section).Key("filenames").String())
 	directory := g.RulesFolder + "/" + g.cfgFile.Section(section).Key("directory").String()
+	if function == "Markov" {
+		g.Omen, err = LoadOmen(directory)
+		if err != nil {
+			return err
+		}
+	}
 
 	for _, curFile := range filenames {
 		filePath := directory + "/" + curFile
@@ -1,112 +1,247 @@
 package manager
 
 import (
+	"strconv"
 	"strings"
 	"unicode"
+	"unicode/utf8"
 )
 
+// GuessIndex generates values of one structure of pre-terminal, value is written to segment guess[guessPointer]
 type GuessIndex struct {
 	replacement  *Replacement
 	function     string
 	topIndex     int
 	guessPointer int
-	Reset        func([]string, bool) ([]string, bool)
-	Next         func([]string, bool) ([]string, bool)
+	omen         *omenIterator
+	// tmp is used for capitalization of non ASCII words, which can change length in bytes
+	tmp []byte
 }
 
-func NewGuessIndex(replacement *Replacement, endOfGuess int) *GuessIndex {
+// NewGuessIndex returns nil for unknown function, Markov structure of grammar without omen grammar has no values,
+// see Grammar.Validate
+func NewGuessIndex(grammar *Grammar, replacement *Replacement, endOfGuess int) *GuessIndex {
 	gi := &GuessIndex{
 		replacement:  replacement,
 		guessPointer: endOfGuess,
 	}
 	gi.function = gi.replacement.Function
-	if gi.function == "Copy" || gi.function == "Shadow" {
-		gi.Reset = gi._resetCopyShadow
-		gi.Next = gi._nextCopyShadow
-	} else if gi.function == "Capitalization" {
-		gi.Reset = gi._resetCapitalization
-		gi.Next = gi._nextCapitalization
-	} else {
+	switch gi.function {
+	case "Copy", "Shadow", "Capitalization":
+	case "Markov":
+		if grammar.Omen != nil {
+			gi.omen = newOmenIterator(grammar.Omen)
+		}
+	default:
 		return nil
 	}
 	return gi
 }
-func (g *GuessIndex) _resetCopyShadow(guess []string, new bool) ([]string, bool) {
-	g.topIndex = 0
-	if len(g.replacement.Values) == 0 {
-		return []string{}, false
+
+// Reset sets first value of structure
+func (g *GuessIndex) Reset(guess [][]byte) bool {
+	switch g.function {
+	case "Capitalization":
+		return g._resetCapitalization(guess)
+	case "Markov":
+		return g.omen != nil && g._resetMarkov(guess)
 	}
-	value := g.replacement.Values[0]
-	if new {
-		guess = append(guess, value)
-	} else {
-		guess[g.guessPointer] = value
+	return g._resetCopyShadow(guess)
+}
+
+// Next sets next value of structure, false means there is no other value
+func (g *GuessIndex) Next(guess [][]byte) bool {
+	switch g.function {
+	case "Capitalization":
+		return g._nextCapitalization(guess)
+	case "Markov":
+		return g.omen != nil && g._nextMarkov(guess)
 	}
-	return guess, true
+	return g._nextCopyShadow(guess)
+}
 
+// Seek sets k-th value of structure
+func (g *GuessIndex) Seek(guess [][]byte, k uint64) bool {
+	switch g.function {
+	case "Capitalization":
+		return g._seekCapitalization(guess, k)
+	case "Markov":
+		return g.omen != nil && g._seekMarkov(guess, k)
+	}
+	return g._seekCopyShadow(guess, k)
 }
 
-func (g *GuessIndex) _resetCapitalization(guess []string, new bool) ([]string, bool) {
-	g.topIndex = 0
-	if len(g.replacement.Values) == 0 {
-		return []string{}, false
+func (g *GuessIndex) Count() uint64 {
+	if g.function != "Markov" {
+		return uint64(len(g.replacement.Values))
 	}
-	rule := g.replacement.Values[0]
-	var tmpString strings.Builder
-	baseWord := guess[g.guessPointer]
-	tmpString.Grow(len(baseWord))
+	if g.omen == nil {
+		return 0
+	}
+	count := uint64(0)
+	for _, v := range g.replacement.Values {
+		count += g.omen.omen.CountLevel(markovLevel(v))
+	}
+	return count
+}
+
+func markovLevel(value string) int32 {
+	level, err := strconv.ParseInt(value, 10, 32)
+	if err != nil {
+		return -1
+	}
+	return int32(level)
+}
+
+func (g *GuessIndex) set(guess [][]byte, value string) {
+	guess[g.guessPointer] = append(guess[g.guessPointer][:0], value...)
+}
+
+// capitalize applies capitalization rule to segment in place
+func (g *GuessIndex) capitalize(guess [][]byte, rule string) {
+	word := guess[g.guessPointer]
+	for i, ch := range word {
+		if ch >= utf8.RuneSelf {
+			g.capitalizeRunes(guess, rule)
+			return
+		}
+		if rule[i] == 'U' {
+			if 'a' <= ch && ch <= 'z' {
+				word[i] = ch - 'a' + 'A'
+			}
+		} else if 'A' <= ch && ch <= 'Z' {
+			word[i] = ch - 'A' + 'a'
+		}
+	}
+}
+
+// capitalizeRunes is slow path of capitalize, rule is applied per rune instead of byte
+func (g *GuessIndex) capitalizeRunes(guess [][]byte, rule string) {
+	word := guess[g.guessPointer]
+	g.tmp = g.tmp[:0]
 	lPos := 0
-	for _, ch := range baseWord {
+	for len(word) > 0 {
+		ch, size := utf8.DecodeRune(word)
+		word = word[size:]
 		if rule[lPos] == 'U' {
-			tmpString.WriteRune(unicode.ToUpper(ch))
+			ch = unicode.ToUpper(ch)
 		} else {
-			tmpString.WriteRune(unicode.ToLower(ch))
+			ch = unicode.ToLower(ch)
 		}
+		g.tmp = append(g.tmp, string(ch)...)
 		lPos++
 	}
-	guess[g.guessPointer] = tmpString.String()
-	return guess, true
+	guess[g.guessPointer], g.tmp = g.tmp, guess[g.guessPointer]
+}
 
+func (g *GuessIndex) _resetCopyShadow(guess [][]byte) bool {
+	g.topIndex = 0
+	if len(g.replacement.Values) == 0 {
+		return false
+	}
+	g.set(guess, g.replacement.Values[0])
+	return true
+
+}
+
+func (g *GuessIndex) _resetCapitalization(guess [][]byte) bool {
+	g.topIndex = 0
+	if len(g.replacement.Values) == 0 {
+		return false
+	}
+	g.capitalize(guess, g.replacement.Values[0])
+	return true
+
+}
+
+func (g *GuessIndex) setMarkov(guess [][]byte) {
+	guess[g.guessPointer] = append(guess[g.guessPointer][:0], g.omen.Value()...)
+}
+
+// _markovFrom finds first level starting at topIndex which contains at least one guess
+func (g *GuessIndex) _markovFrom(guess [][]byte) bool {
+	for ; g.topIndex < len(g.replacement.Values); g.topIndex++ {
+		level := markovLevel(g.replacement.Values[g.topIndex])
+		if level >= 0 && g.omen.Reset(level) {
+			g.setMarkov(guess)
+			return true
+		}
+	}
+	return false
+}
+
+func (g *GuessIndex) _resetMarkov(guess [][]byte) bool {
+	g.topIndex = 0
+	return g._markovFrom(guess)
 }
 
-func (g *GuessIndex) _nextCopyShadow(guess []string, new bool) ([]string, bool) {
+func (g *GuessIndex) _nextMarkov(guess [][]byte) bool {
+	if g.omen.Next() {
+		g.setMarkov(guess)
+		return true
+	}
 	g.topIndex++
-	if g.topIndex >= len(g.replacement.Values) {
-		return guess, false
+	return g._markovFrom(guess)
+}
+
+func (g *GuessIndex) _seekMarkov(guess [][]byte, k uint64) bool {
+	for g.topIndex = 0; g.topIndex < len(g.replacement.Values); g.topIndex++ {
+		level := markovLevel(g.replacement.Values[g.topIndex])
+		count := g.omen.omen.CountLevel(level)
+		if k < count {
+			g.omen.Seek(level, k)
+			g.setMarkov(guess)
+			return true
+		}
+		k -= count
 	}
+	return false
+}
 
-	guess[g.guessPointer] = g.replacement.Values[g.topIndex]
-	return guess, true
+func (g *GuessIndex) _seekCopyShadow(guess [][]byte, k uint64) bool {
+	if k >= uint64(len(g.replacement.Values)) {
+		return false
+	}
+	g.topIndex = int(k)
+	g.set(guess, g.replacement.Values[k])
+	return true
+}
 
+func (g *GuessIndex) _seekCapitalization(guess [][]byte, k uint64) bool {
+	if k >= uint64(len(g.replacement.Values)) {
+		return false
+	}
+	g.topIndex = int(k)
+	g.capitalize(guess, g.replacement.Values[k])
+	return true
 }
 
-func (g *GuessIndex) _nextCapitalization(guess []string, new bool) ([]string, bool) {
+func (g *GuessIndex) _nextCopyShadow(guess [][]byte) bool {
 	g.topIndex++
 	if g.topIndex >= len(g.replacement.Values) {
-		return []string{}, false
+		return false
 	}
-	rule := g.replacement.Values[g.topIndex]
-	var tmpString strings.Builder
-	baseWord := guess[g.guessPointer]
-	tmpString.Grow(len(baseWord))
+	g.set(guess, g.replacement.Values[g.topIndex])
+	return true
 
-	lPos := 0
-	for _, ch := range baseWord {
-		if rule[lPos] == 'U' {
-			tmpString.WriteRune(unicode.ToUpper(ch))
-		} else {
-			tmpString.WriteRune(unicode.ToLower(ch))
-		}
-		lPos++
+}
+
+func (g *GuessIndex) _nextCapitalization(guess [][]byte) bool {
+	g.topIndex++
+	if g.topIndex >= len(g.replacement.Values) {
+		return false
 	}
-	guess[g.guessPointer] = tmpString.String()
-	return guess, true
+	g.capitalize(guess, g.replacement.Values[g.topIndex])
+	return true
 
 }
 
+// GuessGeneration generates guesses of pre-terminal, every structure writes its value to own segment,
+// guess is concatenation of segments and it's built in reusable buffer, so generation doesn't allocate
 type GuessGeneration struct {
 	grammar    *Grammar
-	guess      []string
+	guess      [][]byte
+	buf        []byte
 	structures []*GuessIndex
 }
 
@@ -115,6 +250,9 @@ func NewGuessGeneration(grammar *Grammar, preTerminal *TreeItem) *GuessGeneratio
 		grammar: grammar,
 	}
 	g.Init(preTerminal, 0)
+	if len(g.structures) > 0 {
+		g.guess = make([][]byte, g.structures[len(g.structures)-1].guessPointer+1)
+	}
 	return g
 }
 
@@ -127,7 +265,7 @@ func (g *GuessGeneration) Init(section *TreeItem, endOfGuess int) {
 
 		}
 	} else {
-		g.structures = append(g.structures, NewGuessIndex(replacement, endOfGuess))
+		g.structures = append(g.structures, NewGuessIndex(g.grammar, replacement, endOfGuess))
 		if replacement.Function == "Shadow" {
 			g.Init(section.Childrens[0], endOfGuess)
 		}
@@ -140,37 +278,178 @@ func (g *GuessGeneration) Count() uint64 {
 	}
 	r := uint64(1)
 	for _, s := range g.structures {
-		r *= uint64(len(s.replacement.Values))
+		r *= s.Count()
 	}
 	return r
 
 }
-func (g *GuessGeneration) First() string {
+
+// bytes joins segments to buffer
+func (g *GuessGeneration) bytes() []byte {
+	g.buf = g.buf[:0]
+	for _, segment := range g.guess {
+		g.buf = append(g.buf, segment...)
+	}
+	return g.buf
+}
+
+// FirstBytes returns first guess, returned slice is valid until next call of generation, nil means there is no guess
+func (g *GuessGeneration) FirstBytes() []byte {
+	if len(g.structures) == 0 {
+		return nil
+	}
 	for _, item := range g.structures {
-		guess, ok := item.Reset(g.guess, true)
-		if ok {
-			g.guess = guess
-		} else {
-			return ""
+		if !item.Reset(g.guess) {
+			return nil
 		}
 	}
-	return strings.Join(g.guess, "")
-
+	return g.bytes()
 }
 
-func (g *GuessGeneration) Next() string {
+// NextBytes returns next guess, returned slice is valid until next call of generation, nil means there is no guess
+func (g *GuessGeneration) NextBytes() []byte {
 	for i := len(g.structures) - 1; i >= 0; i-- {
-		guess, ok := g.structures[i].Next(g.guess, false)
-		if ok {
-			g.guess = guess
+		if g.structures[i].Next(g.guess) {
 			for forwardIndex := i + 1; forwardIndex < len(g.structures); forwardIndex++ {
-				guess, ok = g.structures[forwardIndex].Reset(g.guess, false)
-				if ok {
-					g.guess = guess
-				}
+				g.structures[forwardIndex].Reset(g.guess)
+			}
+			return g.bytes()
+		}
+	}
+	return nil
+}
+
+// SeekBytes moves generation to n-th guess of pre-terminal and returns it, NextBytes continues after it
+func (g *GuessGeneration) SeekBytes(n uint64) []byte {
+	if n >= g.Count() {
+		return nil
+	}
+	digits := make([]uint64, len(g.structures))
+	for i := len(g.structures) - 1; i >= 0; i-- {
+		count := g.structures[i].Count()
+		digits[i] = n % count
+		n /= count
+	}
+	for i, item := range g.structures {
+		if !item.Seek(g.guess, digits[i]) {
+			return nil
+		}
+	}
+	return g.bytes()
+}
+
+func (g *GuessGeneration) First() string {
+	return string(g.FirstBytes())
+}
+
+func (g *GuessGeneration) Next() string {
+	return string(g.NextBytes())
+}
+
+// Seek moves generation to n-th guess of pre-terminal, Next continues after it
+func (g *GuessGeneration) Seek(n uint64) string {
+	return string(g.SeekBytes(n))
+}
+
+func indexOf(values []string, value string) (uint64, bool) {
+	for i, v := range values {
+		if v == value {
+			return uint64(i), true
+		}
+	}
+	return 0, false
+}
+
+// index returns index of value of structure, segment is part of guess generated by structure,
+// word is segment of Shadow structure which is capitalized by following Capitalization structure
+func (g *GuessIndex) index(segment, word string) (uint64, bool) {
+	switch g.function {
+	case "Copy":
+		return indexOf(g.replacement.Values, segment)
+	case "Shadow":
+		return indexOf(g.replacement.Values, strings.ToLower(segment))
+	case "Capitalization":
+		mask := capitalizationMask(word)
+		if applyCapitalization(strings.ToLower(word), mask) != word {
+			return 0, false
+		}
+		return indexOf(g.replacement.Values, mask)
+	case "Markov":
+		if g.omen == nil {
+			return 0, false
+		}
+		level, ok := g.omen.omen.Level(segment)
+		if !ok {
+			return 0, false
+		}
+		offset := uint64(0)
+		for _, v := range g.replacement.Values {
+			if markovLevel(v) == level {
+				k, ok := g.omen.omen.rank(segment, level)
+				return offset + k, ok
+			}
+			offset += g.omen.omen.CountLevel(markovLevel(v))
+		}
+	}
+	return 0, false
+}
+
+// Rank returns index of guess in pre-terminal, it's inverse of Seek
+func (g *GuessGeneration) Rank(guess string) (uint64, bool) {
+	if len(g.structures) == 0 {
+		return 0, false
+	}
+	runes := []rune(guess)
+	pos := 0
+	word := ""
+	res := uint64(0)
+	for i, s := range g.structures {
+		segment := word
+		switch s.function {
+		case "Copy", "Shadow":
+			if len(s.replacement.Values) == 0 {
+				return 0, false
+			}
+			// all values of replacement have the same length
+			length := utf8.RuneCountInString(s.replacement.Values[0])
+			if pos+length > len(runes) {
+				return 0, false
+			}
+			segment = string(runes[pos : pos+length])
+			pos += length
+			word = segment
+		case "Markov":
+			// Markov takes rest of guess except structures after it
+			rest, ok := g.fixedLength(i + 1)
+			if !ok || pos+rest > len(runes) {
+				return 0, false
+			}
+			segment = string(runes[pos : len(runes)-rest])
+			pos = len(runes) - rest
+		}
+		k, ok := s.index(segment, word)
+		if !ok {
+			return 0, false
+		}
+		res = res*s.Count() + k
+	}
+	return res, pos == len(runes)
+}
+
+// fixedLength returns number of runes generated by structures starting with structure at index from,
+// it's false if length isn't fixed, i.e. there is Markov structure
+func (g *GuessGeneration) fixedLength(from int) (int, bool) {
+	length := 0
+	for _, s := range g.structures[from:] {
+		switch s.function {
+		case "Copy", "Shadow":
+			if len(s.replacement.Values) == 0 {
+				return 0, false
 			}
-			return strings.Join(g.guess, "")
+			length += utf8.RuneCountInString(s.replacement.Values[0])
+		case "Markov":
+			return 0, false
 		}
 	}
-	return ""
+	return length, true
 }
@@ -0,0 +1,321 @@
+package manager
+
+import (
+	"bufio"
+	"fmt"
+	"io/ioutil"
+	"runtime"
+	"strings"
+	"testing"
+	"time"
+	"unicode"
+)
+
+// tinyRules is fixture grammar with Alpha, Digits, Other and Markov structures
+const tinyRules = "testdata/Tiny"
+
+// loadTiny returns fixture grammar and all its pre-terminals in generation order
+func loadTiny(tb testing.TB) (*Pcfg, []*TreeItem) {
+	tb.Helper()
+	g, err := LoadGrammar(tinyRules)
+	if err != nil {
+		tb.Fatal(err)
+	}
+	p := NewPcfg(g)
+	que, err := NewPcfgQueue(p)
+	if err != nil {
+		tb.Fatal(err)
+	}
+	var preTerminals []*TreeItem
+	for {
+		item, err := que.Next()
+		if err == ErrPriorirtyQueEmpty {
+			return p, preTerminals
+		}
+		if err != nil {
+			tb.Fatal(err)
+		}
+		preTerminals = append(preTerminals, item.Tree)
+	}
+}
+
+// hasMarkov returns true if pre-terminal contains Markov structure
+func hasMarkov(g *Grammar, item *TreeItem) bool {
+	if g.Sections[item.Index].Replacements[item.Transition].Function == "Markov" {
+		return true
+	}
+	for _, child := range item.Childrens {
+		if hasMarkov(g, child) {
+			return true
+		}
+	}
+	return false
+}
+
+// stringGuessGeneration is implementation of guess generation before guesses were built in byte buffer,
+// every structure is string and guess is joined for every call, it's only used to check that
+// GuessGeneration generates same guesses
+type stringGuessGeneration struct {
+	replacements []*Replacement
+	// pointers are indexes of guess modified by structures
+	pointers []int
+	indexes  []int
+	guess    []string
+}
+
+func newStringGuessGeneration(g *Grammar, preTerminal *TreeItem) *stringGuessGeneration {
+	s := &stringGuessGeneration{}
+	s.init(g, preTerminal, 0)
+	return s
+}
+
+func (s *stringGuessGeneration) init(g *Grammar, section *TreeItem, endOfGuess int) {
+	replacement := g.Sections[section.Index].Replacements[section.Transition]
+	if replacement.Function == "Transparent" {
+		for _, child := range section.Childrens {
+			s.init(g, child, endOfGuess)
+			endOfGuess = s.pointers[len(s.pointers)-1] + 1
+		}
+		return
+	}
+	s.replacements = append(s.replacements, replacement)
+	s.pointers = append(s.pointers, endOfGuess)
+	s.indexes = append(s.indexes, 0)
+	if replacement.Function == "Shadow" {
+		s.init(g, section.Childrens[0], endOfGuess)
+	}
+}
+
+func (s *stringGuessGeneration) set(i int) {
+	value := s.replacements[i].Values[s.indexes[i]]
+	p := s.pointers[i]
+	if s.replacements[i].Function != "Capitalization" {
+		if p == len(s.guess) {
+			s.guess = append(s.guess, value)
+		} else {
+			s.guess[p] = value
+		}
+		return
+	}
+	var tmp strings.Builder
+	pos := 0
+	for _, ch := range s.guess[p] {
+		if value[pos] == 'U' {
+			tmp.WriteRune(unicode.ToUpper(ch))
+		} else {
+			tmp.WriteRune(unicode.ToLower(ch))
+		}
+		pos++
+	}
+	s.guess[p] = tmp.String()
+}
+
+func (s *stringGuessGeneration) First() string {
+	for i := range s.replacements {
+		s.indexes[i] = 0
+		s.set(i)
+	}
+	return strings.Join(s.guess, "")
+}
+
+func (s *stringGuessGeneration) Next() string {
+	for i := len(s.replacements) - 1; i >= 0; i-- {
+		if s.indexes[i]+1 >= len(s.replacements[i].Values) {
+			continue
+		}
+		s.indexes[i]++
+		s.set(i)
+		for j := i + 1; j < len(s.replacements); j++ {
+			s.indexes[j] = 0
+			s.set(j)
+		}
+		return strings.Join(s.guess, "")
+	}
+	return ""
+}
+
+// largePreTerminal returns pre-terminal of fixture grammar with structures Alpha, Capitalization and Digits,
+// values of its structures are replaced, so it generates 1000 words * 16 capitalizations * 100 digits
+func largePreTerminal(tb testing.TB) (*Pcfg, *TreeItem) {
+	tb.Helper()
+	p, preTerminals := loadTiny(tb)
+	for _, item := range preTerminals {
+		gen := NewGuessGeneration(p.Grammar, item)
+		if len(gen.structures) != 3 || gen.structures[0].function != "Shadow" ||
+			gen.structures[1].function != "Capitalization" || gen.structures[2].function != "Copy" ||
+			len(gen.structures[0].replacement.Values[0]) != 4 {
+			continue
+		}
+		var words, rules, digits []string
+		for i := 0; i < 1000; i++ {
+			words = append(words, fmt.Sprintf("%c%c%c%c", 'a'+i/100, 'a'+i/10%10, 'a'+i%10, 'z'-i%26))
+		}
+		for i := 0; i < 16; i++ {
+			rule := []byte("LLLL")
+			for j := range rule {
+				if i&(1<<uint(j)) != 0 {
+					rule[j] = 'U'
+				}
+			}
+			rules = append(rules, string(rule))
+		}
+		for i := 0; i < 100; i++ {
+			digits = append(digits, fmt.Sprintf("%02d", i))
+		}
+		gen.structures[0].replacement.Values = words
+		gen.structures[1].replacement.Values = rules
+		gen.structures[2].replacement.Values = digits
+		return p, item
+	}
+	tb.Fatal("fixture grammar doesn't contain pre-terminal A4D2")
+	return nil, nil
+}
+
+// BenchmarkGuessGeneration measures generation of one guess of large pre-terminal, setup of pre-terminal
+// isn't included, so ns/op and allocs/op are per guess
+func BenchmarkGuessGeneration(b *testing.B) {
+	p, item := largePreTerminal(b)
+	gen := NewGuessGeneration(p.Grammar, item)
+	b.ReportAllocs()
+	b.ResetTimer()
+	guess := gen.FirstBytes()
+	for i := 0; i < b.N; i++ {
+		if guess = gen.NextBytes(); guess == nil {
+			guess = gen.FirstBytes()
+		}
+	}
+}
+
+// BenchmarkListTerminalsToWriter measures writing of all guesses of large pre-terminal, ns/guess and allocs/guess
+// are reported
+func BenchmarkListTerminalsToWriter(b *testing.B) {
+	p, item := largePreTerminal(b)
+	count := NewGuessGeneration(p.Grammar, item).Count()
+	buf := bufio.NewWriter(ioutil.Discard)
+	var before, after runtime.MemStats
+	runtime.ReadMemStats(&before)
+	b.ResetTimer()
+	start := time.Now()
+	for i := 0; i < b.N; i++ {
+		if err := p.ListTerminalsToWriter(item, buf); err != nil {
+			b.Fatal(err)
+		}
+	}
+	elapsed := time.Since(start)
+	b.StopTimer()
+	runtime.ReadMemStats(&after)
+	guesses := float64(count) * float64(b.N)
+	b.ReportMetric(float64(elapsed.Nanoseconds())/guesses, "ns/guess")
+	b.ReportMetric(float64(after.Mallocs-before.Mallocs)/guesses, "allocs/guess")
+}
+
+func TestStringGuessGeneration(t *testing.T) {
+	p, all := loadTiny(t)
+	for _, item := range all {
+		if hasMarkov(p.Grammar, item) {
+			continue
+		}
+		gen := NewGuessGeneration(p.Grammar, item)
+		old := newStringGuessGeneration(p.Grammar, item)
+		guess, oldGuess := gen.First(), old.First()
+		for guess != "" || oldGuess != "" {
+			if guess != oldGuess {
+				t.Fatalf("guess %q, string implementation %q", guess, oldGuess)
+			}
+			guess, oldGuess = gen.Next(), old.Next()
+		}
+	}
+}
+
+func TestGuessGenerationSeek(t *testing.T) {
+	p, preTerminals := loadTiny(t)
+	markov := 0
+	for i, item := range preTerminals {
+		if hasMarkov(p.Grammar, item) {
+			markov++
+		}
+		t.Run(fmt.Sprintf("%d", i), func(t *testing.T) {
+			gen := NewGuessGeneration(p.Grammar, item)
+			var guesses []string
+			for guess := gen.First(); guess != ""; guess = gen.Next() {
+				guesses = append(guesses, guess)
+			}
+			if uint64(len(guesses)) != gen.Count() {
+				t.Fatalf("generated %d guesses, count is %d", len(guesses), gen.Count())
+			}
+			for n, want := range guesses {
+				seek := NewGuessGeneration(p.Grammar, item)
+				if got := seek.Seek(uint64(n)); got != want {
+					t.Fatalf("Seek(%d) = %q, want %q", n, got, want)
+				}
+				// generation continues after seek
+				if n+1 < len(guesses) {
+					if got := seek.Next(); got != guesses[n+1] {
+						t.Fatalf("Next after Seek(%d) = %q, want %q", n, got, guesses[n+1])
+					}
+				}
+				if k, ok := gen.Rank(want); !ok || k != uint64(n) {
+					t.Fatalf("Rank(%q) = %d, %t, want %d", want, k, ok, n)
+				}
+			}
+			if got := NewGuessGeneration(p.Grammar, item).Seek(gen.Count()); got != "" {
+				t.Fatalf("Seek(Count) = %q, want no guess", got)
+			}
+		})
+	}
+	if markov == 0 {
+		t.Fatal("fixture grammar doesn't contain Markov pre-terminal")
+	}
+}
+
+func TestUnrank(t *testing.T) {
+	p, _ := loadTiny(t)
+	tests := []struct {
+		position uint64
+		guess    string
+	}{
+		{0, "pass"},
+		{1, "love"},
+		{27, "ab"},
+		{28, "aba"},
+		{99, "pasS"},
+		{249, "FISH12"},
+		{365, "00@SUN"},
+	}
+	positions := make([]uint64, 0, len(tests))
+	passwords := make([]string, 0, len(tests))
+	for _, tt := range tests {
+		positions = append(positions, tt.position)
+		passwords = append(passwords, tt.guess)
+	}
+	guesses, errs := p.UnrankAll(positions)
+	ranks, rankErrs := p.RankAll(passwords)
+	for i, tt := range tests {
+		if errs[i] != nil || guesses[i] != tt.guess {
+			t.Errorf("Unrank(%d) = %q, %v, want %q", tt.position, guesses[i], errs[i], tt.guess)
+		}
+		if rankErrs[i] != nil || ranks[i] != tt.position {
+			t.Errorf("Rank(%q) = %d, %v, want %d", tt.guess, ranks[i], rankErrs[i], tt.position)
+		}
+	}
+	if _, err := p.Unrank(366); err != ErrPriorirtyQueEmpty {
+		t.Errorf("Unrank after last guess returned %v, want %v", err, ErrPriorirtyQueEmpty)
+	}
+}
+
+func TestMissingOmen(t *testing.T) {
+	p, preTerminals := loadTiny(t)
+	p.Grammar.Omen = nil
+	if err := p.Grammar.Validate(); err != ErrMissingOmen {
+		t.Fatalf("Validate() = %v, want %v", err, ErrMissingOmen)
+	}
+	for _, item := range preTerminals {
+		if !hasMarkov(p.Grammar, item) {
+			continue
+		}
+		gen := NewGuessGeneration(p.Grammar, item)
+		if guess := gen.First(); guess != "" || gen.Count() != 0 {
+			t.Errorf("Markov pre-terminal without omen generated %q, count %d", guess, gen.Count())
+		}
+	}
+}
@@ -0,0 +1,149 @@
+package manager
+
+import (
+	"context"
+	"sync/atomic"
+)
+
+// GuessOptions limits guesses returned by GuessIterator, zero values mean no limit
+type GuessOptions struct {
+	// MaxGuesses stops taking new pre-terminals after at least MaxGuesses guesses were generated
+	MaxGuesses uint64
+	// Skip and Limit select exact range of guesses in generation order
+	Skip  uint64
+	Limit uint64
+	// MinProb and MaxProb select pre-terminals with probability in [MinProb, MaxProb)
+	MinProb float64
+	MaxProb float64
+	// Filter drops guesses for which it returns false, skipped guesses are counted to Skip and Limit
+	Filter func(guess []byte) bool
+}
+
+// GuessIterator iterates over guesses of generator in descending order of probability
+type GuessIterator struct {
+	g    *Generator
+	ctx  context.Context
+	opts GuessOptions
+	args InputArgs
+	// end is first guess which isn't returned, 0 means unlimited
+	end     uint64
+	item    PreTerminalItem
+	prob    float64
+	gen     *GuessGeneration
+	left    uint64
+	checked uint64
+	err     error
+	done    bool
+}
+
+// ctxCheckInterval is how many guesses are returned between checks of context
+const ctxCheckInterval = 4096
+
+// Guesses returns iterator over guesses, it's stopped when ctx is cancelled.
+// It shares state with generator, so Run or RunForServer shouldn't be used at the same time.
+func (g *Generator) Guesses(ctx context.Context, opts GuessOptions) *GuessIterator {
+	it := &GuessIterator{
+		g:    g,
+		ctx:  ctx,
+		opts: opts,
+		args: InputArgs{
+			MinProb: opts.MinProb,
+			MaxProb: opts.MaxProb,
+		},
+	}
+	if opts.Limit > 0 {
+		it.end = opts.Skip + opts.Limit
+	}
+	return it
+}
+
+// nextPreTerminal takes next pre-terminal from generator and seeks to first guess which should be returned
+func (it *GuessIterator) nextPreTerminal() bool {
+	for {
+		if err := it.ctx.Err(); err != nil {
+			it.err = err
+			return false
+		}
+		generated := atomic.LoadUint64(&it.g.Generated)
+		if it.opts.MaxGuesses > 0 && generated >= it.opts.MaxGuesses {
+			return false
+		}
+		if it.end > 0 && generated >= it.end {
+			return false
+		}
+		item, err := it.g.next(&it.args)
+		if err != nil {
+			if err != ErrPriorirtyQueEmpty {
+				it.err = err
+			}
+			return false
+		}
+		if item.Position+item.Count <= it.opts.Skip {
+			it.g.Complete(item.Id)
+			continue
+		}
+		offset := uint64(0)
+		if item.Position < it.opts.Skip {
+			offset = it.opts.Skip - item.Position
+		}
+		it.left = item.Count - offset
+		if it.end > 0 && item.Position+item.Count > it.end {
+			it.left -= item.Position + item.Count - it.end
+		}
+		it.item = item
+		it.prob = it.g.Pcfg.FindProbability(item.Item)
+		it.gen = NewGuessGeneration(it.g.Pcfg.Grammar, item.Item)
+		if it.gen.SeekBytes(offset) == nil {
+			it.g.Complete(item.Id)
+			continue
+		}
+		return true
+	}
+}
+
+// Next returns next guess and probability of its pre-terminal, returned slice is valid until next call.
+// ok is false when there is no other guess, Err returns reason.
+func (it *GuessIterator) Next() (guess []byte, prob float64, ok bool) {
+	for !it.done {
+		if it.gen == nil {
+			if !it.nextPreTerminal() {
+				it.done = true
+				break
+			}
+			guess = it.gen.bytes()
+		} else {
+			guess = it.gen.NextBytes()
+		}
+		if guess == nil || it.left == 0 {
+			it.g.Complete(it.item.Id)
+			it.gen = nil
+			continue
+		}
+		it.left--
+		it.checked++
+		if it.checked%ctxCheckInterval == 0 {
+			if err := it.ctx.Err(); err != nil {
+				it.err = err
+				it.done = true
+				break
+			}
+		}
+		if it.opts.Filter != nil && !it.opts.Filter(guess) {
+			continue
+		}
+		return guess, it.prob, true
+	}
+	return nil, 0, false
+}
+
+// Err returns error which stopped iteration, e.g. cancelled context
+func (it *GuessIterator) Err() error {
+	return it.err
+}
+
+// Close stops iteration, unfinished pre-terminal stays pending, so it's part of checkpoint
+func (it *GuessIterator) Close() error {
+	it.done = true
+	it.gen = nil
+	return nil
+}
@@ -37,6 +37,9 @@ func (m *Manager) LoadFromFile(file string) error {
 		return err
 	}
 	grammar := GrammarFromProto(&pbGrammar)
+	if err := grammar.Validate(); err != nil {
+		return err
+	}
 	pcfg := NewPcfg(grammar)
 	m.Generator = NewGenerator(pcfg)
 	return nil
@@ -57,7 +60,9 @@ func (m *Manager) ListTerminals(preTerminalsFile string) error {
 	}
 	for _, item := range pbItems.PreTerminals {
 		treeItem := TreeItemFromProto(item)
-		m.Generator.Pcfg.ListTerminals(treeItem)
+		if err := m.Generator.Pcfg.ListTerminalsToWriter(treeItem, m.Generator.Output); err != nil {
+			return err
+		}
 	}
 	return nil
 }
@@ -67,6 +72,11 @@ func (m *Manager) Start(input *InputArgs) error {
 	//log.Infoln("MaxGuesses: ", input.MaxGuesses)
 	//log.Infoln("Debug: ", input.Debug)
 
+	if input.RestoreFile != "" {
+		if err := m.Generator.LoadCheckpoint(input.RestoreFile); err != nil {
+			return err
+		}
+	}
 	if err := m.Generator.Run(input); err != nil {
 		return err
 	}
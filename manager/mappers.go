@@ -9,6 +9,7 @@ func GrammarToProto(g *Grammar) *proto.Grammar {
 		RulesFolder: g.RulesFolder,
 		Sections:    sectionsToProto(g.Sections),
 		Mapping:     mappingToProto(g.Mapping),
+		Omen:        omenToProto(g.Omen),
 	}
 }
 
@@ -17,6 +18,35 @@ func GrammarFromProto(g *proto.Grammar) *Grammar {
 		RulesFolder: g.RulesFolder,
 		Sections:    sectionsFromProto(g.Sections),
 		Mapping:     mappingFromProto(g.Mapping),
+		Omen:        omenFromProto(g.Omen),
+	}
+}
+
+func omenToProto(o *OmenGrammar) *proto.Omen {
+	if o == nil {
+		return nil
+	}
+	return &proto.Omen{
+		Ngram:    o.Ngram,
+		MaxLevel: o.MaxLevel,
+		Ip:       o.IP,
+		Cp:       o.CP,
+		Ep:       o.EP,
+		Ln:       o.LN,
+	}
+}
+
+func omenFromProto(o *proto.Omen) *OmenGrammar {
+	if o == nil {
+		return nil
+	}
+	return &OmenGrammar{
+		Ngram:    o.Ngram,
+		MaxLevel: o.MaxLevel,
+		IP:       o.Ip,
+		CP:       o.Cp,
+		EP:       o.Ep,
+		LN:       o.Ln,
 	}
 }
 
@@ -51,6 +81,22 @@ func TreeItemFromProto(i *proto.TreeItem) *TreeItem {
 		Childrens:  childrens,
 	}
 }
+func QueueItemToProto(i *QueueItem) *proto.QueueItem {
+	return &proto.QueueItem{
+		IsTerminal:  i.IsTerminal,
+		Probability: i.Probability,
+		Tree:        TreeItemToProto(i.Tree),
+	}
+}
+
+func QueueItemFromProto(i *proto.QueueItem) *QueueItem {
+	return &QueueItem{
+		IsTerminal:  i.IsTerminal,
+		Probability: i.Probability,
+		Tree:        TreeItemFromProto(i.Tree),
+	}
+}
+
 func mappingToProto(m GrammarMapping) map[string]*proto.IntMap {
 	res := make(map[string]*proto.IntMap)
 	for k, v := range m {
@@ -3,16 +3,37 @@ package manager
 import "time"
 
 type InputArgs struct {
-	GoRoutines        uint
-	MaxGuesses        uint64
-	Debug             bool
-	Port              string
-	RulesFolder       string
-	HashFile          string
-	HashcatMode       string
-	TerminalsQueSize  int
-	ChunkStartSize    uint64
-	ChunkDuration     time.Duration
-	GenerateTerminals bool
-	SaveStats         bool
+	GoRoutines uint
+	MaxGuesses uint64
+	// MaxDuration stops taking new pre-terminals after it elapses
+	MaxDuration  time.Duration
+	Skip         uint64
+	Limit        uint64
+	MinProb      float64
+	MaxProb      float64
+	MaxQueueSize int
+	Debug        bool
+	// ProgressInterval is how often is progress reported in debug mode
+	ProgressInterval   time.Duration
+	Port               string
+	RulesFolder        string
+	HashFile           string
+	HashcatMode        string
+	TerminalsQueSize   int
+	ChunkStartSize     uint64
+	ChunkDuration      time.Duration
+	GenerateTerminals  bool
+	SaveStats          bool
+	CheckpointFile     string
+	CheckpointInterval time.Duration
+	RestoreFile        string
+	// Format is output format of guesses, see FormatPlain, FormatTSV and FormatJSONL
+	Format string
+	// Dedupe is DedupeExact or DedupeBloom, bloom filter is sized for DedupeItems with DedupeFalsePositive rate
+	Dedupe              string
+	DedupeItems         uint64
+	DedupeFalsePositive float64
+	// ExcludePotfiles and ExcludeWordlists contain guesses which were already tried
+	ExcludePotfiles  []string
+	ExcludeWordlists []string
 }
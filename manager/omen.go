@@ -0,0 +1,439 @@
+package manager
+
+import (
+	"bufio"
+	"os"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"unicode/utf8"
+
+	"gopkg.in/ini.v1"
+)
+
+// OmenGrammar holds the Markov (OMEN) model used for the "M" base structure.
+// Levels are small integers, lower level means more probable n-gram.
+type OmenGrammar struct {
+	Ngram    int32
+	MaxLevel int32
+	// IP - initial prefixes (ngram-1 characters) -> level
+	IP map[string]int32
+	// CP - conditional probabilities (ngram characters) -> level
+	CP map[string]int32
+	// EP - end probabilities (ngram-1 characters) -> level, used only for scoring
+	EP map[string]int32
+	// LN - level of length, LN[0] is level of length 1
+	LN []int32
+
+	once     sync.Once
+	ipLevels [][]string
+	cpLevels map[string][][]string
+	mu       sync.RWMutex
+	memo     map[omenKey]uint64
+}
+
+type omenKey struct {
+	context string
+	steps   int
+	level   int32
+}
+
+type omenOption struct {
+	value string
+	level int32
+}
+
+func LoadOmen(directory string) (*OmenGrammar, error) {
+	cfg, err := ini.Load(directory + "/config.txt")
+	if err != nil {
+		return nil, err
+	}
+	o := &OmenGrammar{}
+	ngram, err := cfg.Section("training_settings").Key("ngram").Int()
+	if err != nil {
+		return nil, err
+	}
+	o.Ngram = int32(ngram)
+	o.MaxLevel = int32(cfg.Section("training_settings").Key("max_level").MustInt(10))
+	if o.IP, err = loadOmenLevels(directory + "/IP.level"); err != nil {
+		return nil, err
+	}
+	if o.CP, err = loadOmenLevels(directory + "/CP.level"); err != nil {
+		return nil, err
+	}
+	if o.EP, err = loadOmenLevels(directory + "/EP.level"); err != nil {
+		return nil, err
+	}
+	file, err := os.Open(directory + "/LN.level")
+	if err != nil {
+		return nil, err
+	}
+	defer file.Close()
+	scanner := bufio.NewScanner(file)
+	for scanner.Scan() {
+		level, err := strconv.ParseInt(strings.TrimSpace(scanner.Text()), 10, 32)
+		if err != nil {
+			return nil, err
+		}
+		o.LN = append(o.LN, int32(level))
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, err
+	}
+	return o, nil
+}
+
+func loadOmenLevels(fileName string) (map[string]int32, error) {
+	file, err := os.Open(fileName)
+	if err != nil {
+		return nil, err
+	}
+	defer file.Close()
+
+	res := make(map[string]int32)
+	scanner := bufio.NewScanner(file)
+	for scanner.Scan() {
+		line := scanner.Text()
+		splitted := strings.SplitN(line, "\t", 2)
+		if len(splitted) != 2 {
+			return nil, ErrOmenLevel
+		}
+		level, err := strconv.ParseInt(splitted[0], 10, 32)
+		if err != nil {
+			return nil, err
+		}
+		res[splitted[1]] = int32(level)
+	}
+	return res, scanner.Err()
+}
+
+// index builds lookup tables sorted by level and value, so every client enumerates guesses in the same order
+func (o *OmenGrammar) index() {
+	o.once.Do(func() {
+		o.memo = make(map[omenKey]uint64)
+		for prefix, level := range o.IP {
+			o.ipLevels = appendLevel(o.ipLevels, level, prefix)
+		}
+		for _, l := range o.ipLevels {
+			sort.Strings(l)
+		}
+		o.cpLevels = make(map[string][][]string)
+		for ngram, level := range o.CP {
+			_, size := utf8.DecodeLastRuneInString(ngram)
+			context := ngram[:len(ngram)-size]
+			o.cpLevels[context] = appendLevel(o.cpLevels[context], level, ngram[len(ngram)-size:])
+		}
+		for _, levels := range o.cpLevels {
+			for _, l := range levels {
+				sort.Strings(l)
+			}
+		}
+	})
+}
+
+func appendLevel(levels [][]string, level int32, value string) [][]string {
+	for int32(len(levels)) <= level {
+		levels = append(levels, nil)
+	}
+	levels[level] = append(levels[level], value)
+	return levels
+}
+
+func shiftContext(context, next string) string {
+	_, size := utf8.DecodeRuneInString(context)
+	return context[size:] + next
+}
+
+// ways returns number of strings with steps characters following context, whose conditional levels sum to level
+func (o *OmenGrammar) ways(context string, steps int, level int32) uint64 {
+	if steps == 0 {
+		if level == 0 {
+			return 1
+		}
+		return 0
+	}
+	key := omenKey{context: context, steps: steps, level: level}
+	o.mu.RLock()
+	res, ok := o.memo[key]
+	o.mu.RUnlock()
+	if ok {
+		return res
+	}
+	levels := o.cpLevels[context]
+	for l := int32(0); l <= level && int(l) < len(levels); l++ {
+		for _, next := range levels[l] {
+			res += o.ways(shiftContext(context, next), steps-1, level-l)
+		}
+	}
+	o.mu.Lock()
+	o.memo[key] = res
+	o.mu.Unlock()
+	return res
+}
+
+func (o *OmenGrammar) lengthLevel(length int) (int32, bool) {
+	if length < int(o.Ngram)-1 || length < 1 || length > len(o.LN) {
+		return 0, false
+	}
+	return o.LN[length-1], true
+}
+
+// options returns choices at given depth which lead to at least one guess
+func (o *OmenGrammar) options(context string, depth, length int, remaining int32) []omenOption {
+	var res []omenOption
+	if depth == 0 {
+		steps := length - int(o.Ngram) + 1
+		for l := int32(0); l <= remaining && int(l) < len(o.ipLevels); l++ {
+			for _, prefix := range o.ipLevels[l] {
+				if o.ways(prefix, steps, remaining-l) > 0 {
+					res = append(res, omenOption{value: prefix, level: l})
+				}
+			}
+		}
+		return res
+	}
+	steps := length - int(o.Ngram) + 1 - depth
+	levels := o.cpLevels[context]
+	for l := int32(0); l <= remaining && int(l) < len(levels); l++ {
+		for _, next := range levels[l] {
+			if o.ways(shiftContext(context, next), steps, remaining-l) > 0 {
+				res = append(res, omenOption{value: next, level: l})
+			}
+		}
+	}
+	return res
+}
+
+func (o *OmenGrammar) countLength(length int, level int32) uint64 {
+	lnLevel, ok := o.lengthLevel(length)
+	if !ok || lnLevel > level {
+		return 0
+	}
+	o.index()
+	remaining := level - lnLevel
+	steps := length - int(o.Ngram) + 1
+	res := uint64(0)
+	for l := int32(0); l <= remaining && int(l) < len(o.ipLevels); l++ {
+		for _, prefix := range o.ipLevels[l] {
+			res += o.ways(prefix, steps, remaining-l)
+		}
+	}
+	return res
+}
+
+// CountLevel returns how many guesses have exactly given level
+func (o *OmenGrammar) CountLevel(level int32) uint64 {
+	res := uint64(0)
+	for length := 1; length <= len(o.LN); length++ {
+		res += o.countLength(length, level)
+	}
+	return res
+}
+
+type omenChoice struct {
+	options   []omenOption
+	index     int
+	remaining int32
+}
+
+// omenIterator enumerates all guesses of one level, ordered by length, then by level and value of every choice
+type omenIterator struct {
+	omen    *OmenGrammar
+	level   int32
+	length  int
+	choices []omenChoice
+	buf     []byte
+}
+
+func newOmenIterator(omen *OmenGrammar) *omenIterator {
+	omen.index()
+	return &omenIterator{
+		omen: omen,
+	}
+}
+
+func (it *omenIterator) context(depth int) string {
+	// every choice at depth > 0 adds one character, context are last ngram-1 characters
+	res := it.choices[0].options[it.choices[0].index].value
+	for d := 1; d < depth; d++ {
+		res = shiftContext(res, it.choices[d].options[it.choices[d].index].value)
+	}
+	return res
+}
+
+// fill chooses first option at every depth starting from depth
+func (it *omenIterator) fill(depth int) {
+	for ; depth < len(it.choices); depth++ {
+		prev := it.choices[depth-1]
+		remaining := prev.remaining - prev.options[prev.index].level
+		it.choices[depth] = omenChoice{
+			options:   it.omen.options(it.context(depth), depth, it.length, remaining),
+			remaining: remaining,
+		}
+	}
+}
+
+func (it *omenIterator) startLength(length int) bool {
+	lnLevel, ok := it.omen.lengthLevel(length)
+	if !ok || lnLevel > it.level {
+		return false
+	}
+	it.length = length
+	it.choices = make([]omenChoice, length-int(it.omen.Ngram)+2)
+	it.choices[0] = omenChoice{
+		options:   it.omen.options("", 0, length, it.level-lnLevel),
+		remaining: it.level - lnLevel,
+	}
+	if len(it.choices[0].options) == 0 {
+		return false
+	}
+	it.fill(1)
+	return true
+}
+
+func (it *omenIterator) Reset(level int32) bool {
+	it.level = level
+	for length := 1; length <= len(it.omen.LN); length++ {
+		if it.startLength(length) {
+			return true
+		}
+	}
+	return false
+}
+
+func (it *omenIterator) Next() bool {
+	for d := len(it.choices) - 1; d >= 0; d-- {
+		it.choices[d].index++
+		if it.choices[d].index < len(it.choices[d].options) {
+			it.fill(d + 1)
+			return true
+		}
+	}
+	for length := it.length + 1; length <= len(it.omen.LN); length++ {
+		if it.startLength(length) {
+			return true
+		}
+	}
+	return false
+}
+
+// Seek sets iterator to k-th guess of level
+func (it *omenIterator) Seek(level int32, k uint64) bool {
+	it.level = level
+	for length := 1; length <= len(it.omen.LN); length++ {
+		count := it.omen.countLength(length, level)
+		if k >= count {
+			k -= count
+			continue
+		}
+		it.startLength(length)
+		for d := range it.choices {
+			if d > 0 {
+				prev := it.choices[d-1]
+				remaining := prev.remaining - prev.options[prev.index].level
+				it.choices[d] = omenChoice{
+					options:   it.omen.options(it.context(d), d, it.length, remaining),
+					remaining: remaining,
+				}
+			}
+			choice := &it.choices[d]
+			steps := length - int(it.omen.Ngram) + 1 - d
+			for choice.index = 0; choice.index < len(choice.options); choice.index++ {
+				option := choice.options[choice.index]
+				context := option.value
+				if d > 0 {
+					context = shiftContext(it.context(d), option.value)
+				}
+				completions := it.omen.ways(context, steps, choice.remaining-option.level)
+				if k < completions {
+					break
+				}
+				k -= completions
+			}
+		}
+		return true
+	}
+	return false
+}
+
+// Value returns actual guess, returned slice is valid until next call of iterator
+func (it *omenIterator) Value() []byte {
+	it.buf = it.buf[:0]
+	for _, ch := range it.choices {
+		it.buf = append(it.buf, ch.options[ch.index].value...)
+	}
+	return it.buf
+}
+
+// Level computes level of guess, returns false when guess can't be generated by omen
+func (o *OmenGrammar) Level(guess string) (int32, bool) {
+	runes := []rune(guess)
+	lnLevel, ok := o.lengthLevel(len(runes))
+	if !ok {
+		return 0, false
+	}
+	prefixLen := int(o.Ngram) - 1
+	ipLevel, ok := o.IP[string(runes[:prefixLen])]
+	if !ok {
+		return 0, false
+	}
+	level := lnLevel + ipLevel
+	for i := prefixLen; i < len(runes); i++ {
+		cpLevel, ok := o.CP[string(runes[i-prefixLen:i+1])]
+		if !ok {
+			return 0, false
+		}
+		level += cpLevel
+	}
+	return level, true
+}
+
+// rank returns index of guess in enumeration of level, it's inverse of omenIterator.Seek
+func (o *OmenGrammar) rank(guess string, level int32) (uint64, bool) {
+	runes := []rune(guess)
+	length := len(runes)
+	lnLevel, ok := o.lengthLevel(length)
+	if !ok || lnLevel > level {
+		return 0, false
+	}
+	o.index()
+	res := uint64(0)
+	for l := 1; l < length; l++ {
+		res += o.countLength(l, level)
+	}
+	prefixLen := int(o.Ngram) - 1
+	remaining := level - lnLevel
+	context := string(runes[:prefixLen])
+	found := false
+	for _, option := range o.options("", 0, length, remaining) {
+		if option.value == context {
+			remaining -= option.level
+			found = true
+			break
+		}
+		res += o.ways(option.value, length-prefixLen, remaining-option.level)
+	}
+	if !found {
+		return 0, false
+	}
+	for d := 1; d <= length-prefixLen; d++ {
+		next := string(runes[prefixLen+d-1])
+		steps := length - prefixLen - d
+		found = false
+		for _, option := range o.options(context, d, length, remaining) {
+			nextContext := shiftContext(context, option.value)
+			if option.value == next {
+				context = nextContext
+				remaining -= option.level
+				found = true
+				break
+			}
+			res += o.ways(nextContext, steps, remaining-option.level)
+		}
+		if !found {
+			return 0, false
+		}
+	}
+	return res, remaining == 0
+}
@@ -6,10 +6,13 @@ import (
 	"io"
 	"os"
 	"strings"
+	"sync"
 )
 
 type Pcfg struct {
-	Grammar *Grammar
+	Grammar        *Grammar
+	valueIndexOnce sync.Once
+	valueIndex     []map[string]valuePosition
 }
 
 func NewPcfg(g *Grammar) *Pcfg {
@@ -33,34 +36,46 @@ func (p *Pcfg) StartIndex() int32 {
 	return -1
 }
 
-func (p *Pcfg) ListTerminals(preTerminal *TreeItem) (uint64, string, string) {
+// ListTerminalsToWriter writes newline separated guesses of pre-terminal to w
+func (p *Pcfg) ListTerminalsToWriter(preTerminal *TreeItem, w io.Writer) error {
 	guessGeneration := NewGuessGeneration(p.Grammar, preTerminal)
-	guess := guessGeneration.First()
-	guesses := uint64(0)
-	first := guess
-	last := guess
-	buf := bufio.NewWriter(os.Stdout)
-	for guess != "" {
-		//fmt.Println(guess)
-		fmt.Fprintln(buf, guess)
-		guess = guessGeneration.Next()
-		last = guess
-		guesses++
+	buf := bufferedWriter(w)
+	for guess := guessGeneration.FirstBytes(); guess != nil; guess = guessGeneration.NextBytes() {
+		if err := writeLine(buf, guess); err != nil {
+			return err
+		}
 	}
-	buf.Flush()
-	//fmt.Println(guesses)
-	return guesses, first, last
+	return buf.Flush()
 }
 
-func (p *Pcfg) ListTerminalsToWriter(preTerminal *TreeItem, w io.Writer) error {
+// bufferedWriter returns w if it's already buffered, so buffer isn't allocated for every pre-terminal
+func bufferedWriter(w io.Writer) *bufio.Writer {
+	if buf, ok := w.(*bufio.Writer); ok {
+		return buf
+	}
+	return bufio.NewWriter(w)
+}
+
+func writeLine(buf *bufio.Writer, guess []byte) error {
+	if _, err := buf.Write(guess); err != nil {
+		return err
+	}
+	return buf.WriteByte('\n')
+}
+
+// ListTerminalsRangeToWriter writes count guesses of pre-terminal starting with guess at offset
+func (p *Pcfg) ListTerminalsRangeToWriter(preTerminal *TreeItem, w io.Writer, offset, count uint64) error {
+	if count == 0 {
+		return nil
+	}
 	guessGeneration := NewGuessGeneration(p.Grammar, preTerminal)
-	guess := guessGeneration.First()
-	buf := bufio.NewWriter(w)
-	for guess != "" {
-		if _, err := fmt.Fprintln(buf, guess); err != nil {
+	guess := guessGeneration.SeekBytes(offset)
+	buf := bufferedWriter(w)
+	for i := uint64(0); i < count && guess != nil; i++ {
+		if err := writeLine(buf, guess); err != nil {
 			return err
 		}
-		guess = guessGeneration.Next()
+		guess = guessGeneration.NextBytes()
 	}
 	return buf.Flush()
 }
@@ -0,0 +1,214 @@
+package manager
+
+import (
+	"bufio"
+	"bytes"
+	"github.com/sirupsen/logrus"
+	"io"
+	"math"
+	"sync"
+)
+
+const (
+	// chunkSize is size of output chunk passed from worker to writer
+	chunkSize = 64 * 1024
+	// chunksPerJob is how many chunks can worker generate ahead of writer
+	chunksPerJob = 4
+	// maxUnflushedJobs is how many jobs can be written to buffer before it's flushed and jobs are reported as written
+	maxUnflushedJobs = 1024
+)
+
+// job is part of pre-terminal which should be generated, worker sends output in chunks to out
+type job struct {
+	PreTerminalItem
+	offset uint64
+	count  uint64
+	out    chan []byte
+}
+
+// chunkWriter splits output of worker to chunks
+type chunkWriter struct {
+	out chan<- []byte
+	buf []byte
+}
+
+func (w *chunkWriter) Write(p []byte) (int, error) {
+	w.buf = append(w.buf, p...)
+	if len(w.buf) >= chunkSize {
+		w.out <- w.buf
+		w.buf = make([]byte, 0, chunkSize+4096)
+	}
+	return len(p), nil
+}
+
+func (w *chunkWriter) Close() {
+	if len(w.buf) > 0 {
+		w.out <- w.buf
+	}
+	close(w.out)
+}
+
+// pipeline generates pre-terminals by multiple workers and writes their output in the same order as they were added
+type pipeline struct {
+	pcfg      *Pcfg
+	jobs      chan *job
+	order     chan *job
+	format    string
+	dedupe    *Deduper
+	wg        sync.WaitGroup
+	writerErr chan error
+	written   func(j *job)
+}
+
+// newPipeline starts workers and writer, guesses are deduplicated if dedupe isn't nil
+func newPipeline(pcfg *Pcfg, goRoutines uint, output io.Writer, format string, dedupe *Deduper, written func(j *job)) *pipeline {
+	if goRoutines == 0 {
+		goRoutines = 1
+	}
+	p := &pipeline{
+		pcfg:      pcfg,
+		format:    format,
+		dedupe:    dedupe,
+		jobs:      make(chan *job, goRoutines),
+		order:     make(chan *job, goRoutines),
+		writerErr: make(chan error, 1),
+		written:   written,
+	}
+	p.wg.Add(int(goRoutines))
+	for w := uint(1); w <= goRoutines; w++ {
+		go func() {
+			p.worker()
+			p.wg.Done()
+		}()
+	}
+	go func() {
+		p.writerErr <- p.writer(output)
+	}()
+	return p
+}
+
+func (p *pipeline) Add(j *job) {
+	j.out = make(chan []byte, chunksPerJob)
+	p.order <- j
+	p.jobs <- j
+}
+
+// Close waits until all jobs are written
+func (p *pipeline) Close() error {
+	close(p.jobs)
+	close(p.order)
+	p.wg.Wait()
+	return <-p.writerErr
+}
+
+func (p *pipeline) worker() {
+	format := p.format
+	if p.dedupe != nil {
+		// writer formats guesses after deduplication
+		format = FormatPlain
+	}
+	// buffer is shared by jobs of worker
+	buf := bufio.NewWriter(nil)
+	for j := range p.jobs {
+		w := &chunkWriter{out: j.out, buf: make([]byte, 0, chunkSize+4096)}
+		buf.Reset(w)
+		// ordinal is 1-based number of guess in generation order
+		ordinal := j.Position + j.offset + 1
+		if err := p.pcfg.ListAnnotatedTerminalsRangeToWriter(j.Item, buf, format, j.offset, j.count, ordinal); err != nil {
+			logrus.Warn(err)
+		}
+		w.Close()
+	}
+
+}
+
+// writer writes output of jobs in order, job is reported as written only after its output was flushed,
+// so checkpoint doesn't contain guesses which are still in buffer or weren't written because of error
+func (p *pipeline) writer(output io.Writer) error {
+	buf := bufio.NewWriterSize(output, chunkSize)
+	var err error
+	var unflushed []*job
+	flush := func() {
+		if err == nil {
+			err = buf.Flush()
+		}
+		if err == nil && p.written != nil {
+			for _, j := range unflushed {
+				p.written(j)
+			}
+		}
+		unflushed = unflushed[:0]
+	}
+	for {
+		var j *job
+		var ok bool
+		select {
+		case j, ok = <-p.order:
+		default:
+			// nothing to write now, so buffered output is flushed
+			flush()
+			j, ok = <-p.order
+		}
+		if !ok {
+			break
+		}
+		if p.dedupe != nil {
+			err = p.writeDeduped(buf, j, err)
+		}
+		for chunk := range j.out {
+			if err == nil {
+				_, err = buf.Write(chunk)
+			}
+		}
+		if err == nil {
+			unflushed = append(unflushed, j)
+		}
+		if len(unflushed) >= maxUnflushedJobs {
+			flush()
+		}
+	}
+	flush()
+	return err
+}
+
+// writeDeduped writes guesses of job which weren't seen yet, output of job is consumed even after error
+func (p *pipeline) writeDeduped(buf *bufio.Writer, j *job, err error) error {
+	a := p.pcfg.newAnnotator(j.Item, buf, p.format, j.Position+j.offset+1)
+	// line can be split between chunks
+	var partial []byte
+	for chunk := range j.out {
+		for err == nil && len(chunk) > 0 {
+			i := bytes.IndexByte(chunk, '\n')
+			if i == -1 {
+				partial = append(partial, chunk...)
+				break
+			}
+			line := chunk[:i]
+			if len(partial) > 0 {
+				partial = append(partial, line...)
+				line = partial
+			}
+			if p.dedupe.Keep(line) {
+				err = a.write(line)
+			} else {
+				a.skip()
+			}
+			partial = partial[:0]
+			chunk = chunk[i+1:]
+		}
+	}
+	return err
+}
+
+// ListTerminalsParallel generates pre-terminals by goRoutines workers and writes them to w in given order,
+// duplicate guesses are dropped if dedupe isn't nil
+func (p *Pcfg) ListTerminalsParallel(preTerminals []*TreeItem, w io.Writer, goRoutines uint, dedupe *Deduper) error {
+	pl := newPipeline(p, goRoutines, w, FormatPlain, dedupe, nil)
+	for _, preTerminal := range preTerminals {
+		pl.Add(&job{
+			PreTerminalItem: PreTerminalItem{Item: preTerminal},
+			count:           math.MaxUint64,
+		})
+	}
+	return pl.Close()
+}
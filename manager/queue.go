@@ -5,6 +5,7 @@ import (
 	"errors"
 	"fmt"
 	log "github.com/sirupsen/logrus"
+	"sort"
 )
 
 type QueueItem struct {
@@ -36,9 +37,40 @@ func (pq PriorityQueue) Len() int {
 }
 
 func (pq PriorityQueue) Less(i, j int) bool {
+	if pq[i].Probability == pq[j].Probability {
+		// ties are broken by tree, so order doesn't depend on history of heap (e.g. restored checkpoint)
+		return compareTree(pq[i].Tree, pq[j].Tree) < 0
+	}
 	return pq[i].Probability > pq[j].Probability
 }
 
+func compareTree(a, b *TreeItem) int {
+	if a.Index != b.Index {
+		if a.Index < b.Index {
+			return -1
+		}
+		return 1
+	}
+	if a.Transition != b.Transition {
+		if a.Transition < b.Transition {
+			return -1
+		}
+		return 1
+	}
+	if len(a.Childrens) != len(b.Childrens) {
+		if len(a.Childrens) < len(b.Childrens) {
+			return -1
+		}
+		return 1
+	}
+	for i := range a.Childrens {
+		if c := compareTree(a.Childrens[i], b.Childrens[i]); c != 0 {
+			return c
+		}
+	}
+	return 0
+}
+
 func (pq *PriorityQueue) Pop() interface{} {
 	old := *pq
 	n := len(old)
@@ -57,10 +89,13 @@ func (pq PriorityQueue) Swap(i, j int) {
 }
 
 type PcfqQueue struct {
-	pQue    PriorityQueue
-	pcfg    *Pcfg
+	pQue PriorityQueue
+	pcfg *Pcfg
+	// minProb is floor of queue, items with lower or equal probability were dropped and queue is rebuilt after it's empty
 	minProb float64
 	maxProb float64
+	// maxSize is maximum of items in queue, 0 means unlimited
+	maxSize int
 }
 
 func NewPcfgQueue(pcfg *Pcfg) (*PcfqQueue, error) {
@@ -70,6 +105,15 @@ func NewPcfgQueue(pcfg *Pcfg) (*PcfqQueue, error) {
 		maxProb: 1.0,
 	}
 	heap.Init(&q.pQue)
+	qItem, err := q.startItem()
+	if err != nil {
+		return nil, err
+	}
+	heap.Push(&q.pQue, qItem)
+	return q, nil
+}
+
+func (q *PcfqQueue) startItem() (*QueueItem, error) {
 	index := q.pcfg.StartIndex()
 	if index == -1 {
 		return nil, errors.New("could not find starting position for the Pcfg")
@@ -78,20 +122,81 @@ func NewPcfgQueue(pcfg *Pcfg) (*PcfqQueue, error) {
 		Index:      index,
 		Transition: 0,
 	}
-	qItem := &QueueItem{
+	return &QueueItem{
 		IsTerminal:  false,
-		Probability: pcfg.FindProbability(tree),
+		Probability: q.pcfg.FindProbability(tree),
 		Tree:        tree,
+	}, nil
+}
+
+// SetMaxSize limits size of queue, low probability items are dropped when it's exceeded
+func (q *PcfqQueue) SetMaxSize(size int) {
+	q.maxSize = size
+	q.trim()
+}
+
+// trim drops low probability items when queue is bigger than maxSize, the highest dropped probability becomes floor
+func (q *PcfqQueue) trim() {
+	if q.maxSize <= 0 || q.pQue.Len() <= q.maxSize {
+		return
 	}
-	heap.Push(&q.pQue, qItem)
-	return q, nil
+	sort.Sort(q.pQue)
+	keep := q.maxSize / 2
+	floor := q.pQue[keep].Probability
+	// items with same probability as floor are dropped too, rebuild finds all of them
+	for keep > 0 && q.pQue[keep-1].Probability == floor {
+		keep--
+	}
+	if keep == 0 {
+		heap.Init(&q.pQue)
+		return
+	}
+	log.Debugf("trimming queue from %d to %d items, floor: %g", q.pQue.Len(), keep, floor)
+	// sorted slice is valid heap, it's copied so dropped items can be freed
+	q.pQue = append(PriorityQueue(nil), q.pQue[:keep]...)
+	q.minProb = floor
+}
+
+// rebuild walks parse trees from START node and pushes items between floor and maxProb,
+// items above floor were already processed, because queue is empty
+func (q *PcfqQueue) rebuild() error {
+	ceiling := q.minProb
+	q.minProb = 0
+	root, err := q.startItem()
+	if err != nil {
+		return err
+	}
+	log.Debugf("rebuilding queue, ceiling: %g", ceiling)
+	stack := []*QueueItem{root}
+	for len(stack) > 0 {
+		var item *QueueItem
+		item, stack = stack[len(stack)-1], stack[:len(stack)-1]
+		if item.Probability > ceiling {
+			for _, child := range q.pcfg.DeadbeatDad(item.Tree) {
+				stack = append(stack, &QueueItem{
+					IsTerminal:  q.pcfg.FindIsTerminal(child),
+					Probability: q.pcfg.FindProbability(child),
+					Tree:        child,
+				})
+			}
+		} else if item.Probability > q.minProb {
+			heap.Push(&q.pQue, item)
+			q.trim()
+		}
+	}
+	log.Debugf("queue rebuilt, size: %d, floor: %g", q.pQue.Len(), q.minProb)
+	return nil
 }
 
 func (q *PcfqQueue) Next() (*QueueItem, error) {
 	for {
 		for q.pQue.Len() == 0 {
-			// Rebuild
-			return nil, ErrPriorirtyQueEmpty
+			if q.minProb == 0 {
+				return nil, ErrPriorirtyQueEmpty
+			}
+			if err := q.rebuild(); err != nil {
+				return nil, err
+			}
 		}
 		item := heap.Pop(&q.pQue).(*QueueItem)
 		q.maxProb = item.Probability
@@ -113,10 +218,41 @@ func (q *PcfqQueue) AddChildrenToQueue(item *QueueItem) {
 			Probability: q.pcfg.FindProbability(child),
 			Tree:        child,
 		}
-		if childNode.Probability <= item.Probability {
-			heap.Push(&q.pQue, childNode)
-		} else {
+		if childNode.Probability > item.Probability {
 			log.Warnf("trying to push a parent and not a child on the list")
+		} else if childNode.Probability > q.minProb {
+			// items below floor are found again by rebuild
+			heap.Push(&q.pQue, childNode)
 		}
 	}
+	q.trim()
+}
+
+// Items returns items which are in queue, in no particular order
+func (q *PcfqQueue) Items() []*QueueItem {
+	items := make([]*QueueItem, len(q.pQue))
+	copy(items, q.pQue)
+	return items
+}
+
+// Restore replaces content of queue, it's used for resuming from checkpoint
+func (q *PcfqQueue) Restore(items []*QueueItem, maxProb, minProb float64) {
+	q.pQue = make(PriorityQueue, len(items))
+	copy(q.pQue, items)
+	heap.Init(&q.pQue)
+	q.maxProb = maxProb
+	q.minProb = minProb
+}
+
+// Len returns number of items in queue
+func (q *PcfqQueue) Len() int {
+	return q.pQue.Len()
+}
+
+func (q *PcfqQueue) MinProb() float64 {
+	return q.minProb
+}
+
+func (q *PcfqQueue) MaxProb() float64 {
+	return q.maxProb
 }
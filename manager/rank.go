@@ -0,0 +1,127 @@
+package manager
+
+import "sort"
+
+// Unrank returns guess at position n (0-based) in generation order, pre-terminals are walked until the one containing n is found
+func (p *Pcfg) Unrank(n uint64) (string, error) {
+	guesses, errs := p.UnrankAll([]uint64{n})
+	return guesses[0], errs[0]
+}
+
+// UnrankAll returns guesses at positions (0-based) in generation order and error of every position,
+// pre-terminals are walked only once for all positions
+func (p *Pcfg) UnrankAll(positions []uint64) ([]string, []error) {
+	guesses := make([]string, len(positions))
+	errs := make([]error, len(positions))
+	// order contains indexes of positions which aren't found yet, sorted by position
+	order := make([]int, len(positions))
+	for i := range order {
+		order[i] = i
+	}
+	sort.Slice(order, func(a, b int) bool {
+		return positions[order[a]] < positions[order[b]]
+	})
+	que, err := NewPcfgQueue(p)
+	if err != nil {
+		for i := range errs {
+			errs[i] = err
+		}
+		return guesses, errs
+	}
+	position := uint64(0)
+	for len(order) > 0 {
+		item, err := que.Next()
+		if err != nil {
+			for _, i := range order {
+				errs[i] = err
+			}
+			break
+		}
+		guessGeneration := NewGuessGeneration(p.Grammar, item.Tree)
+		count := guessGeneration.Count()
+		for len(order) > 0 && positions[order[0]] < position+count {
+			guesses[order[0]] = guessGeneration.Seek(positions[order[0]] - position)
+			order = order[1:]
+		}
+		position += count
+	}
+	return guesses, errs
+}
+
+// Rank returns position (0-based) of first occurrence of guess in generation order,
+// pre-terminals are walked until one of derivations of guess is found
+func (p *Pcfg) Rank(guess string) (uint64, error) {
+	positions, errs := p.RankAll([]string{guess})
+	return positions[0], errs[0]
+}
+
+// RankAll returns positions (0-based) of first occurrences of guesses in generation order and error of every guess,
+// pre-terminals are walked only once until derivations of all guesses are found
+func (p *Pcfg) RankAll(guesses []string) ([]uint64, []error) {
+	positions := make([]uint64, len(guesses))
+	errs := make([]error, len(guesses))
+	// derivations maps pre-terminal to indexes of guesses which can be derived by it
+	derivations := make(map[string][]int)
+	// derivation with the highest probability is the latest pre-terminal which can be first occurrence of guess
+	maxProb := make([]float64, len(guesses))
+	var pending []int
+	for i, guess := range guesses {
+		trees := p.Parse(guess)
+		if len(trees) == 0 {
+			errs[i] = ErrNoDerivation
+			continue
+		}
+		for _, tree := range trees {
+			key := debugItem(tree)
+			derivations[key] = append(derivations[key], i)
+			if prob := p.FindProbability(tree); prob > maxProb[i] {
+				maxProb[i] = prob
+			}
+		}
+		pending = append(pending, i)
+	}
+	// guesses with lower probability are found later
+	sort.Slice(pending, func(a, b int) bool {
+		return maxProb[pending[a]] > maxProb[pending[b]]
+	})
+	done := make([]bool, len(guesses))
+	que, err := NewPcfgQueue(p)
+	if err != nil {
+		for _, i := range pending {
+			errs[i] = err
+		}
+		return positions, errs
+	}
+	position := uint64(0)
+	for len(pending) > 0 {
+		item, err := que.Next()
+		if err != nil {
+			for _, i := range pending {
+				if !done[i] {
+					errs[i] = err
+				}
+			}
+			break
+		}
+		// all derivations of guess were already generated
+		for len(pending) > 0 && (done[pending[0]] || item.Probability < maxProb[pending[0]]) {
+			if !done[pending[0]] {
+				errs[pending[0]] = ErrNoDerivation
+				done[pending[0]] = true
+			}
+			pending = pending[1:]
+		}
+		guessGeneration := NewGuessGeneration(p.Grammar, item.Tree)
+		for _, i := range derivations[debugItem(item.Tree)] {
+			if done[i] {
+				continue
+			}
+			if k, ok := guessGeneration.Rank(guesses[i]); ok {
+				positions[i] = position + k
+				done[i] = true
+			}
+		}
+		position += guessGeneration.Count()
+	}
+	return positions, errs
+}
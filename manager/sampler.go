@@ -0,0 +1,139 @@
+package manager
+
+import (
+	"math"
+	"math/rand"
+	"sort"
+)
+
+// Sampler draws random guesses from grammar, replacement is chosen in proportion to probability of all its values
+// and value of replacement uniformly, so every guess is drawn with its probability.
+// Every Markov level with at least one guess has probability of replacement, it's divided among guesses of level.
+type Sampler struct {
+	pcfg       *Pcfg
+	rng        *rand.Rand
+	cumulative [][]float64
+}
+
+func NewSampler(pcfg *Pcfg, rng *rand.Rand) *Sampler {
+	s := &Sampler{
+		pcfg:       pcfg,
+		rng:        rng,
+		cumulative: make([][]float64, len(pcfg.Grammar.Sections)),
+	}
+	for i, section := range pcfg.Grammar.Sections {
+		cumulative := make([]float64, len(section.Replacements))
+		total := 0.0
+		for j, r := range section.Replacements {
+			total += s.weight(r)
+			cumulative[j] = total
+		}
+		s.cumulative[i] = cumulative
+	}
+	return s
+}
+
+// weight returns sum of probabilities of all guesses of replacement
+func (s *Sampler) weight(r *Replacement) float64 {
+	if r.Function != "Markov" {
+		return r.Probability * float64(len(r.Values))
+	}
+	return r.Probability * float64(len(s.markovLevels(r)))
+}
+
+// markovLevels returns levels of Markov replacement which contain at least one guess
+func (s *Sampler) markovLevels(r *Replacement) []int32 {
+	omen := s.pcfg.Grammar.Omen
+	if omen == nil {
+		return nil
+	}
+	var levels []int32
+	for _, v := range r.Values {
+		if level := markovLevel(v); level >= 0 && omen.CountLevel(level) > 0 {
+			levels = append(levels, level)
+		}
+	}
+	return levels
+}
+
+// pick returns transition of section and probability that it was picked
+func (s *Sampler) pick(section int32) (int32, float64) {
+	cumulative := s.cumulative[section]
+	total := cumulative[len(cumulative)-1]
+	x := s.rng.Float64() * total
+	t := sort.SearchFloat64s(cumulative, x)
+	if t >= len(cumulative) {
+		t = len(cumulative) - 1
+	}
+	prev := 0.0
+	if t > 0 {
+		prev = cumulative[t-1]
+	}
+	return int32(t), (cumulative[t] - prev) / total
+}
+
+// SampleTree returns random pre-terminal and its probability
+func (s *Sampler) SampleTree() (*TreeItem, float64) {
+	tree, _ := s.sampleTree(s.pcfg.StartIndex())
+	return tree, s.pcfg.FindProbability(tree)
+}
+
+// Sample returns random guess and probability of its pre-terminal
+func (s *Sampler) Sample() (string, float64) {
+	tree, prob := s.SampleTree()
+	guessGeneration := NewGuessGeneration(s.pcfg.Grammar, tree)
+	if guessGeneration.Count() == 0 {
+		return "", 0
+	}
+	// index is mixed radix number of values of structures, see GuessGeneration.Seek
+	index := uint64(0)
+	for _, structure := range guessGeneration.structures {
+		index = index*structure.Count() + s.randomValue(structure)
+	}
+	return guessGeneration.Seek(index), prob
+}
+
+// randomValue returns index of random value of structure, value of Markov structure is drawn from random level
+func (s *Sampler) randomValue(structure *GuessIndex) uint64 {
+	if structure.function != "Markov" {
+		return s.uniform(structure.Count())
+	}
+	levels := s.markovLevels(structure.replacement)
+	level := levels[s.uniform(uint64(len(levels)))]
+	// guesses of levels are ordered as values of replacement
+	offset := uint64(0)
+	for _, v := range structure.replacement.Values {
+		if l := markovLevel(v); l == level {
+			break
+		} else if l >= 0 {
+			offset += s.pcfg.Grammar.Omen.CountLevel(l)
+		}
+	}
+	return offset + s.uniform(s.pcfg.Grammar.Omen.CountLevel(level))
+}
+
+// uniform returns random number in [0, n)
+func (s *Sampler) uniform(n uint64) uint64 {
+	if n <= math.MaxInt64 {
+		return uint64(s.rng.Int63n(int64(n)))
+	}
+	return s.rng.Uint64() % n
+}
+
+// sampleTree returns random parse tree starting at section and probability of sampling it
+func (s *Sampler) sampleTree(section int32) (*TreeItem, float64) {
+	t, prob := s.pick(section)
+	tree := &TreeItem{
+		Index:      section,
+		Transition: t,
+	}
+	replacement := s.pcfg.Grammar.Sections[section].Replacements[t]
+	if !replacement.IsTerminal {
+		for _, pos := range replacement.Pos {
+			child, childProb := s.sampleTree(pos)
+			tree.Append(child)
+			prob *= childProb
+		}
+	}
+	return tree, prob
+}
@@ -0,0 +1,34 @@
+package manager
+
+import (
+	"math"
+	"math/rand"
+	"testing"
+)
+
+func TestSample(t *testing.T) {
+	p, _ := loadTiny(t)
+	s := NewSampler(p, rand.New(rand.NewSource(1)))
+	const samples = 100000
+	counts := make(map[string]int)
+	for i := 0; i < samples; i++ {
+		guess, prob := s.Sample()
+		_, score, err := p.Score(guess)
+		if err != nil || math.Abs(prob-score) > 1e-12 {
+			t.Fatalf("Sample() = %q %v, score is %v, %v", guess, prob, score, err)
+		}
+		counts[guess]++
+	}
+	// guesses without Markov structure are drawn with their probability
+	for _, guess := range []string{"pass", "love", "pass12", "Cat1", "LOVE", "12!Sun", "FISH12", "00@SUN"} {
+		_, prob, err := p.Score(guess)
+		if err != nil {
+			t.Fatal(err)
+		}
+		want := prob * samples
+		// 4 standard deviations of binomial distribution
+		if diff := math.Abs(float64(counts[guess]) - want); diff > 4*math.Sqrt(want)+1 {
+			t.Errorf("%q was drawn %d times, want %.0f", guess, counts[guess], want)
+		}
+	}
+}
@@ -0,0 +1,229 @@
+package manager
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+	"unicode"
+)
+
+type valuePosition struct {
+	transition int32
+	index      int
+}
+
+// buildValueIndex maps every value of every section to its replacement, it's used for parsing passwords
+func (p *Pcfg) buildValueIndex() {
+	p.valueIndexOnce.Do(func() {
+		p.valueIndex = make([]map[string]valuePosition, len(p.Grammar.Sections))
+		for i, s := range p.Grammar.Sections {
+			if s.Type == "START" {
+				continue
+			}
+			p.valueIndex[i] = make(map[string]valuePosition)
+			for t, r := range s.Replacements {
+				for j, v := range r.Values {
+					if _, ok := p.valueIndex[i][v]; !ok {
+						p.valueIndex[i][v] = valuePosition{transition: int32(t), index: j}
+					}
+				}
+			}
+		}
+	})
+}
+
+func (p *Pcfg) lookupValue(section int32, value string) (valuePosition, bool) {
+	p.buildValueIndex()
+	pos, ok := p.valueIndex[section][value]
+	return pos, ok
+}
+
+func capitalizationMask(word string) string {
+	var mask strings.Builder
+	for _, ch := range word {
+		if unicode.IsUpper(ch) {
+			mask.WriteByte('U')
+		} else {
+			mask.WriteByte('L')
+		}
+	}
+	return mask.String()
+}
+
+func applyCapitalization(word, rule string) string {
+	var res strings.Builder
+	lPos := 0
+	for _, ch := range word {
+		if rule[lPos] == 'U' {
+			res.WriteRune(unicode.ToUpper(ch))
+		} else {
+			res.WriteRune(unicode.ToLower(ch))
+		}
+		lPos++
+	}
+	return res.String()
+}
+
+// parseSegment finds tree item of one non terminal, which generates segment
+func (p *Pcfg) parseSegment(section int32, segment string) (*TreeItem, bool) {
+	s := p.Grammar.Sections[section]
+	if len(s.Replacements) == 0 {
+		return nil, false
+	}
+	switch s.Replacements[0].Function {
+	case "Copy":
+		pos, ok := p.lookupValue(section, segment)
+		if !ok {
+			return nil, false
+		}
+		return &TreeItem{Index: section, Transition: pos.transition}, true
+	case "Shadow":
+		word := strings.ToLower(segment)
+		pos, ok := p.lookupValue(section, word)
+		if !ok {
+			return nil, false
+		}
+		replacement := s.Replacements[pos.transition]
+		if len(replacement.Pos) == 0 {
+			return nil, false
+		}
+		mask := capitalizationMask(segment)
+		capPos, ok := p.lookupValue(replacement.Pos[0], mask)
+		if !ok || applyCapitalization(word, mask) != segment {
+			return nil, false
+		}
+		return &TreeItem{
+			Index:      section,
+			Transition: pos.transition,
+			Childrens:  []*TreeItem{{Index: replacement.Pos[0], Transition: capPos.transition}},
+		}, true
+	case "Markov":
+		if p.Grammar.Omen == nil {
+			return nil, false
+		}
+		level, ok := p.Grammar.Omen.Level(segment)
+		if !ok {
+			return nil, false
+		}
+		pos, ok := p.lookupValue(section, strconv.Itoa(int(level)))
+		if !ok {
+			return nil, false
+		}
+		return &TreeItem{Index: section, Transition: pos.transition}, true
+	}
+	return nil, false
+}
+
+// Parse returns every parse tree (derivation) of password under grammar
+func (p *Pcfg) Parse(password string) []*TreeItem {
+	var trees []*TreeItem
+	startIndex := p.StartIndex()
+	if startIndex == -1 {
+		return trees
+	}
+	runes := []rune(password)
+	for t, r := range p.Grammar.Sections[startIndex].Replacements {
+		base := r.Values[0]
+		nonTerminals := splitBaseToNonTerminals(base)
+		if base == "M" {
+			nonTerminals = []string{"M" + strconv.Itoa(len(runes))}
+		}
+		if len(nonTerminals) != len(r.Pos) {
+			continue
+		}
+		tree := &TreeItem{
+			Index:      startIndex,
+			Transition: int32(t),
+		}
+		start := 0
+		for i, nonTerminal := range nonTerminals {
+			length, err := strconv.Atoi(nonTerminal[1:])
+			if err != nil || start+length > len(runes) {
+				tree = nil
+				break
+			}
+			child, ok := p.parseSegment(r.Pos[i], string(runes[start:start+length]))
+			if !ok {
+				tree = nil
+				break
+			}
+			tree.Append(child)
+			start += length
+		}
+		if tree != nil && start == len(runes) {
+			trees = append(trees, tree)
+		}
+	}
+	return trees
+}
+
+// Score returns the most probable parse tree of password and its probability
+func (p *Pcfg) Score(password string) (*TreeItem, float64, error) {
+	var best *TreeItem
+	bestProb := 0.0
+	for _, tree := range p.Parse(password) {
+		prob := p.FindProbability(tree)
+		if best == nil || prob > bestProb {
+			best = tree
+			bestProb = prob
+		}
+	}
+	if best == nil {
+		return nil, 0, ErrNoDerivation
+	}
+	return best, bestProb, nil
+}
+
+// DescribeTree returns human readable parse tree of password, e.g. A4D2 -> BASE_A[4]=pass (CAPITALIZATION[4]=ULLL) BASE_D[2]=12
+func (p *Pcfg) DescribeTree(tree *TreeItem, password string) string {
+	start := p.Grammar.Sections[tree.Index]
+	var res strings.Builder
+	res.WriteString(start.Replacements[tree.Transition].Values[0])
+	res.WriteString(" ->")
+	runes := []rune(password)
+	pos := 0
+	for _, child := range tree.Childrens {
+		section := p.Grammar.Sections[child.Index]
+		length := len(runes) - pos
+		if section.Name != "markov_prob" {
+			length, _ = strconv.Atoi(section.Name)
+		}
+		if pos+length > len(runes) {
+			length = len(runes) - pos
+		}
+		segment := string(runes[pos : pos+length])
+		pos += length
+		fmt.Fprintf(&res, " %s[%s]=%s", section.Type, section.Name, segment)
+		for _, capItem := range child.Childrens {
+			capSection := p.Grammar.Sections[capItem.Index]
+			fmt.Fprintf(&res, " (%s[%s]=%s)", capSection.Type, capSection.Name, capitalizationMask(segment))
+		}
+	}
+	return res.String()
+}
+
+// maxDescribedValues is how many values of replacement are shown by DescribeStructure
+const maxDescribedValues = 3
+
+// DescribeStructure returns parse tree with one line per node, e.g. BASE_D[2] #0 p=0.2 values(2): 12, 11
+func (p *Pcfg) DescribeStructure(tree *TreeItem) string {
+	var res strings.Builder
+	p.describeNode(&res, tree, 0)
+	return res.String()
+}
+
+func (p *Pcfg) describeNode(res *strings.Builder, tree *TreeItem, depth int) {
+	section := p.Grammar.Sections[tree.Index]
+	replacement := section.Replacements[tree.Transition]
+	values := replacement.Values
+	more := ""
+	if len(values) > maxDescribedValues {
+		values = values[:maxDescribedValues]
+		more = ", ..."
+	}
+	fmt.Fprintf(res, "%s%s[%s] #%d p=%g values(%d): %s%s\n", strings.Repeat("  ", depth), section.Type, section.Name,
+		tree.Transition, replacement.Probability, len(replacement.Values), strings.Join(values, ", "), more)
+	for _, child := range tree.Childrens {
+		p.describeNode(res, child, depth+1)
+	}
+}
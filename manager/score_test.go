@@ -0,0 +1,57 @@
+package manager
+
+import (
+	"math"
+	"testing"
+)
+
+func TestScore(t *testing.T) {
+	p, _ := loadTiny(t)
+	tests := []struct {
+		password string
+		base     string
+		prob     float64
+	}{
+		{"pass", "A4", 0.2 * 0.3 * 0.7},
+		{"pass12", "A4D2", 0.3 * 0.3 * 0.7 * 0.3},
+		{"FISH12", "A4D2", 0.3 * 0.1 * 0.05 * 0.3},
+		{"Cat1", "A3D1", 0.2 * 0.4 * 0.15 * 0.3},
+		{"12!Sun", "D2O1A3", 0.15 * 0.3 * 0.5 * 0.3 * 0.15},
+		// Markov guess of level 0
+		{"ab", "M", 0.15 * 0.05},
+		{"aba", "M", 0.15 * 0.05},
+		// value isn't in grammar
+		{"pass13", "", 0},
+		// capitalization isn't in grammar
+		{"PaSS", "", 0},
+		// Markov level isn't in grammar
+		{"ccc", "", 0},
+		// letter isn't in alphabet of omen grammar
+		{"x", "", 0},
+		{"", "", 0},
+	}
+	for _, tt := range tests {
+		t.Run(tt.password, func(t *testing.T) {
+			trees := p.Parse(tt.password)
+			tree, prob, err := p.Score(tt.password)
+			if tt.base == "" {
+				if len(trees) != 0 || err != ErrNoDerivation || prob != 0 {
+					t.Fatalf("Score(%q) = %v, %v with %d derivations, want %v", tt.password, prob, err, len(trees), ErrNoDerivation)
+				}
+				return
+			}
+			if len(trees) != 1 {
+				t.Fatalf("Parse(%q) returned %d derivations, want 1", tt.password, len(trees))
+			}
+			if err != nil {
+				t.Fatalf("Score(%q) = %v", tt.password, err)
+			}
+			if base := p.BaseStructure(tree); base != tt.base || math.Abs(prob-tt.prob) > 1e-12 {
+				t.Errorf("Score(%q) = %s %v, want %s %v", tt.password, base, prob, tt.base, tt.prob)
+			}
+			if got := p.FindProbability(trees[0]); math.Abs(got-prob) > 1e-12 {
+				t.Errorf("probability of derivation is %v, score is %v", got, prob)
+			}
+		})
+	}
+}
@@ -0,0 +1,80 @@
+package manager
+
+import (
+	"math"
+	"math/rand"
+	"sort"
+)
+
+// GuessNumberEstimator estimates how many guesses are generated before password with given probability,
+// it uses Monte Carlo method from Dell'Amico & Filippone - Monte Carlo Strength Evaluation: Fast and Reliable Password Checking
+type GuessNumberEstimator struct {
+	pcfg *Pcfg
+	// probabilities of samples in descending order
+	probs []float64
+	// ranks[i] is estimated number of guesses with probability >= probs[i]
+	ranks []float64
+}
+
+type estimatorSample struct {
+	prob   float64
+	weight float64
+}
+
+func NewGuessNumberEstimator(pcfg *Pcfg, samples int, rng *rand.Rand) *GuessNumberEstimator {
+	e := &GuessNumberEstimator{
+		pcfg: pcfg,
+	}
+	startIndex := pcfg.StartIndex()
+	if startIndex == -1 || samples <= 0 {
+		return e
+	}
+	s := NewSampler(pcfg, rng)
+	sampled := make([]estimatorSample, 0, samples)
+	for i := 0; i < samples; i++ {
+		tree, sampleProb := s.sampleTree(startIndex)
+		// every guess of pre-terminal has same probability, so whole pre-terminal is weighted by count of its guesses
+		count := NewGuessGeneration(pcfg.Grammar, tree).Count()
+		if count == 0 || sampleProb == 0 {
+			continue
+		}
+		sampled = append(sampled, estimatorSample{
+			prob:   pcfg.FindProbability(tree),
+			weight: float64(count) / sampleProb,
+		})
+	}
+	sort.Slice(sampled, func(i, j int) bool {
+		return sampled[i].prob > sampled[j].prob
+	})
+	e.probs = make([]float64, len(sampled))
+	e.ranks = make([]float64, len(sampled))
+	rank := 0.0
+	// samples without guesses add nothing to rank, but they are still part of the average
+	for i, it := range sampled {
+		rank += it.weight / float64(samples)
+		e.probs[i] = it.prob
+		e.ranks[i] = rank
+	}
+	return e
+}
+
+// GuessNumber returns estimated number of guesses with higher probability than prob
+func (e *GuessNumberEstimator) GuessNumber(prob float64) float64 {
+	// first sample with probability <= prob
+	idx := sort.Search(len(e.probs), func(i int) bool {
+		return e.probs[i] <= prob
+	})
+	if idx == 0 {
+		return 0
+	}
+	return e.ranks[idx-1]
+}
+
+// EstimatePassword returns estimated guess number of password, +Inf is returned if grammar can't generate it
+func (e *GuessNumberEstimator) EstimatePassword(password string) (float64, error) {
+	_, prob, err := e.pcfg.Score(password)
+	if err != nil {
+		return math.Inf(1), err
+	}
+	return e.GuessNumber(prob), nil
+}
@@ -0,0 +1,32 @@
+package manager
+
+import (
+	"math"
+	"math/rand"
+	"testing"
+)
+
+func TestGuessNumberEstimator(t *testing.T) {
+	p, _ := loadTiny(t)
+	e := NewGuessNumberEstimator(p, 20000, rand.New(rand.NewSource(1)))
+	passwords := []string{"pass", "love", "pass12", "ab", "aba", "Cat1", "LOVE", "12!Sun", "FISH12", "00@SUN"}
+	ranks, errs := p.RankAll(passwords)
+	for i, password := range passwords {
+		if errs[i] != nil {
+			t.Fatal(errs[i])
+		}
+		estimate, err := e.EstimatePassword(password)
+		if err != nil {
+			t.Fatalf("EstimatePassword(%q) = %v", password, err)
+		}
+		// estimate counts guesses with higher probability, exact rank also counts guesses
+		// with the same probability which are generated before password
+		rank := float64(ranks[i])
+		if math.Abs(estimate-rank) > 0.1*rank+2 {
+			t.Errorf("EstimatePassword(%q) = %.1f, exact rank is %d", password, estimate, ranks[i])
+		}
+	}
+	if estimate, err := e.EstimatePassword("pass13"); err != ErrNoDerivation || !math.IsInf(estimate, 1) {
+		t.Errorf("EstimatePassword of unknown password = %v, %v, want +Inf, %v", estimate, err, ErrNoDerivation)
+	}
+}
@@ -0,0 +1,235 @@
+package manager
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+	"unicode/utf8"
+)
+
+type counter map[string]uint64
+
+type Trainer struct {
+	Passwords      uint64
+	baseStructures counter
+	alpha          map[int]counter
+	capitalization map[int]counter
+	digits         map[int]counter
+	other          map[int]counter
+}
+
+type trainerSection struct {
+	config       string
+	name         string
+	function     string
+	directory    string
+	fileType     string
+	isTerminal   bool
+	replacements string
+	counters     map[int]counter
+}
+
+func NewTrainer() *Trainer {
+	return &Trainer{
+		baseStructures: make(counter),
+		alpha:          make(map[int]counter),
+		capitalization: make(map[int]counter),
+		digits:         make(map[int]counter),
+		other:          make(map[int]counter),
+	}
+}
+
+func (c counter) add(value string) {
+	c[value]++
+}
+
+func addToLength(counters map[int]counter, length int, value string) {
+	if _, ok := counters[length]; !ok {
+		counters[length] = make(counter)
+	}
+	counters[length].add(value)
+}
+
+// runeType returns class of rune, only ASCII letters are alpha like in the reference PCFG trainer,
+// so grammar has the same base structures as upstream rule sets, other letters are O
+func runeType(r rune) byte {
+	if ('a' <= r && r <= 'z') || ('A' <= r && r <= 'Z') {
+		return 'A'
+	}
+	if r >= '0' && r <= '9' {
+		return 'D'
+	}
+	return 'O'
+}
+
+// Parse reads training passwords, one per line
+func (t *Trainer) Parse(r io.Reader) error {
+	scanner := bufio.NewScanner(r)
+	for scanner.Scan() {
+		t.AddPassword(strings.TrimSuffix(scanner.Text(), "\r"))
+	}
+	return scanner.Err()
+}
+
+// AddPassword splits password to A/D/O runs and updates counters, e.g. Pass12! => A4D2O1
+func (t *Trainer) AddPassword(password string) {
+	// tab is used as separator in grammar files
+	if password == "" || !utf8.ValidString(password) || strings.ContainsRune(password, '\t') {
+		return
+	}
+	t.Passwords++
+	var base strings.Builder
+	runes := []rune(password)
+	for start := 0; start < len(runes); {
+		kind := runeType(runes[start])
+		end := start + 1
+		for end < len(runes) && runeType(runes[end]) == kind {
+			end++
+		}
+		run := runes[start:end]
+		length := end - start
+		base.WriteByte(kind)
+		base.WriteString(strconv.Itoa(length))
+		switch kind {
+		case 'A':
+			mask := make([]byte, length)
+			for i, r := range run {
+				if 'A' <= r && r <= 'Z' {
+					mask[i] = 'U'
+				} else {
+					mask[i] = 'L'
+				}
+			}
+			addToLength(t.alpha, length, strings.ToLower(string(run)))
+			addToLength(t.capitalization, length, string(mask))
+		case 'D':
+			addToLength(t.digits, length, string(run))
+		default:
+			addToLength(t.other, length, string(run))
+		}
+		start = end
+	}
+	t.baseStructures.add(base.String())
+}
+
+// Save writes Rules folder which can be loaded by LoadGrammar
+func (t *Trainer) Save(rulesFolder string) error {
+	sections := []trainerSection{
+		{config: "BASE_A", name: "A", function: "Shadow", directory: "Alpha", isTerminal: false,
+			replacements: `[{"Config_id": "CAPITALIZATION", "Transition_id": "Capitalization"}]`, counters: t.alpha},
+		{config: "BASE_D", name: "D", function: "Copy", directory: "Digits", isTerminal: true, counters: t.digits},
+		{config: "BASE_O", name: "O", function: "Copy", directory: "Other", isTerminal: true, counters: t.other},
+		{config: "CAPITALIZATION", name: "C", function: "Capitalization", directory: "Capitalization", isTerminal: true,
+			counters: t.capitalization},
+	}
+	if err := os.MkdirAll(rulesFolder, 0755); err != nil {
+		return err
+	}
+	config, err := os.Create(filepath.Join(rulesFolder, "config.ini"))
+	if err != nil {
+		return err
+	}
+	defer config.Close()
+	w := bufio.NewWriter(config)
+	fmt.Fprintf(w, "[TRAINING_DATASET_DETAILS]\n")
+	fmt.Fprintf(w, "comments = Trained by pcfg-manager\n")
+	fmt.Fprintf(w, "number_of_passwords = %d\n", t.Passwords)
+	fmt.Fprintf(w, "date = %s\n\n", time.Now().Format(time.RFC3339))
+
+	if err := writeProbabilities(filepath.Join(rulesFolder, "Grammar", "Grammar.txt"), t.baseStructures); err != nil {
+		return err
+	}
+	writeConfigSection(w, trainerSection{config: "START", name: "Base Structure", function: "Transparent",
+		directory: "Grammar", fileType: "Flat", isTerminal: false,
+		replacements: `[{"Config_id": "BASE_A", "Transition_id": "A"}, {"Config_id": "BASE_D", "Transition_id": "D"}, {"Config_id": "BASE_O", "Transition_id": "O"}]`,
+	}, []string{"Grammar.txt"})
+
+	for _, s := range sections {
+		lengths := make([]int, 0, len(s.counters))
+		for l := range s.counters {
+			lengths = append(lengths, l)
+		}
+		sort.Ints(lengths)
+		filenames := make([]string, 0, len(lengths))
+		for _, l := range lengths {
+			name := strconv.Itoa(l) + ".txt"
+			if err := writeProbabilities(filepath.Join(rulesFolder, s.directory, name), s.counters[l]); err != nil {
+				return err
+			}
+			filenames = append(filenames, name)
+		}
+		writeConfigSection(w, s, filenames)
+	}
+	if err := w.Flush(); err != nil {
+		return err
+	}
+	return config.Close()
+}
+
+func writeConfigSection(w io.Writer, s trainerSection, filenames []string) {
+	quoted := make([]string, 0, len(filenames))
+	for _, f := range filenames {
+		quoted = append(quoted, `"`+f+`"`)
+	}
+	fmt.Fprintf(w, "[%s]\n", s.config)
+	fmt.Fprintf(w, "name = %s\n", s.name)
+	fmt.Fprintf(w, "function = %s\n", s.function)
+	fmt.Fprintf(w, "directory = %s\n", s.directory)
+	if s.fileType == "" {
+		s.fileType = "Length"
+	}
+	fmt.Fprintf(w, "file_type = %s\n", s.fileType)
+	fmt.Fprintf(w, "inject_type = Wordlist\n")
+	if s.isTerminal {
+		fmt.Fprintf(w, "is_terminal = True\n")
+	} else {
+		fmt.Fprintf(w, "is_terminal = False\n")
+		fmt.Fprintf(w, "replacements = %s\n", s.replacements)
+	}
+	fmt.Fprintf(w, "filenames = [%s]\n\n", strings.Join(quoted, ", "))
+}
+
+// writeProbabilities writes values in descending probability order, as it's required by Grammar.InsertTerminal
+func writeProbabilities(fileName string, c counter) error {
+	type item struct {
+		value string
+		count uint64
+	}
+	items := make([]item, 0, len(c))
+	total := uint64(0)
+	for v, count := range c {
+		items = append(items, item{value: v, count: count})
+		total += count
+	}
+	sort.Slice(items, func(i, j int) bool {
+		if items[i].count != items[j].count {
+			return items[i].count > items[j].count
+		}
+		return items[i].value < items[j].value
+	})
+	if err := os.MkdirAll(filepath.Dir(fileName), 0755); err != nil {
+		return err
+	}
+	file, err := os.Create(fileName)
+	if err != nil {
+		return err
+	}
+	defer file.Close()
+	w := bufio.NewWriter(file)
+	for _, it := range items {
+		prob := float64(it.count) / float64(total)
+		if _, err := fmt.Fprintf(w, "%s\t%s\n", it.value, strconv.FormatFloat(prob, 'g', -1, 64)); err != nil {
+			return err
+		}
+	}
+	if err := w.Flush(); err != nil {
+		return err
+	}
+	return file.Close()
+}
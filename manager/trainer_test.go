@@ -0,0 +1,71 @@
+package manager
+
+import (
+	"math"
+	"strings"
+	"testing"
+)
+
+func TestTrainerSave(t *testing.T) {
+	trainer := NewTrainer()
+	// é isn't ASCII letter, so it's part of other structure
+	passwords := "pass12\npass12\nLove12\ncafé!\n12\n"
+	if err := trainer.Parse(strings.NewReader(passwords)); err != nil {
+		t.Fatal(err)
+	}
+	rules := t.TempDir()
+	if err := trainer.Save(rules); err != nil {
+		t.Fatal(err)
+	}
+	g, err := LoadGrammar(rules)
+	if err != nil {
+		t.Fatal(err)
+	}
+	p := NewPcfg(g)
+
+	wantBases := map[string]float64{"A4D2": 0.6, "A3O2": 0.2, "D2": 0.2}
+	bases := make(map[string]float64)
+	for _, r := range g.Sections[p.StartIndex()].Replacements {
+		for _, v := range r.Values {
+			bases[v] = r.Probability
+		}
+	}
+	if len(bases) != len(wantBases) {
+		t.Errorf("base structures %v, want %v", bases, wantBases)
+	}
+	for base, want := range wantBases {
+		if got, ok := bases[base]; !ok || math.Abs(got-want) > 1e-9 {
+			t.Errorf("probability of base structure %s is %v, want %v", base, got, want)
+		}
+	}
+
+	tests := []struct {
+		password string
+		base     string
+		prob     float64
+	}{
+		{"pass12", "A4D2", 0.6 * 2.0 / 3 * 2.0 / 3},
+		{"Love12", "A4D2", 0.6 * 1.0 / 3 * 1.0 / 3},
+		{"café!", "A3O2", 0.2},
+		{"12", "D2", 0.2},
+		// capitalization ULL wasn't trained
+		{"Café!", "", 0},
+		{"pass1", "", 0},
+	}
+	for _, tt := range tests {
+		tree, prob, err := p.Score(tt.password)
+		if tt.base == "" {
+			if err != ErrNoDerivation {
+				t.Errorf("Score(%q) = %v, want %v", tt.password, err, ErrNoDerivation)
+			}
+			continue
+		}
+		if err != nil {
+			t.Errorf("Score(%q) = %v", tt.password, err)
+			continue
+		}
+		if base := p.BaseStructure(tree); base != tt.base || math.Abs(prob-tt.prob) > 1e-9 {
+			t.Errorf("Score(%q) = %s %v, want %s %v", tt.password, base, prob, tt.base, tt.prob)
+		}
+	}
+}
@@ -0,0 +1,20 @@
+//go:build !windows
+// +build !windows
+
+package output
+
+import (
+	"io"
+	"os"
+	"syscall"
+)
+
+// openFifo creates named pipe if it doesn't exist, open blocks until reader opens it
+func openFifo(path string) (io.WriteCloser, error) {
+	if _, err := os.Stat(path); os.IsNotExist(err) {
+		if err := syscall.Mkfifo(path, 0644); err != nil {
+			return nil, err
+		}
+	}
+	return os.OpenFile(path, os.O_WRONLY, 0)
+}
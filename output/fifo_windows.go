@@ -0,0 +1,13 @@
+//go:build windows
+// +build windows
+
+package output
+
+import (
+	"errors"
+	"io"
+)
+
+func openFifo(path string) (io.WriteCloser, error) {
+	return nil, errors.New("named pipes are not supported on windows")
+}
@@ -0,0 +1,132 @@
+package output
+
+import (
+	"compress/gzip"
+	"errors"
+	"fmt"
+	"github.com/klauspost/compress/zstd"
+	"io"
+	"net"
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+var (
+	ErrRotate = errors.New("rotation is supported only by file, gzip and zstd sinks")
+)
+
+// kinds are prefixes of sinks, spec with other prefix is path of file, e.g. C:\guesses.txt
+var kinds = map[string]bool{
+	"file": true,
+	"gzip": true,
+	"zstd": true,
+	"fifo": true,
+	"tcp":  true,
+}
+
+type stdoutSink struct{}
+
+func (stdoutSink) Write(p []byte) (int, error) {
+	return os.Stdout.Write(p)
+}
+
+// Close doesn't close stdout, it can be still used by logger or other commands
+func (stdoutSink) Close() error {
+	return nil
+}
+
+// compressedSink closes compressor before underlying file
+type compressedSink struct {
+	io.WriteCloser
+	file *os.File
+}
+
+func (s *compressedSink) Close() error {
+	if err := s.WriteCloser.Close(); err != nil {
+		s.file.Close()
+		return err
+	}
+	return s.file.Close()
+}
+
+// Open returns sink specified as kind:target, e.g. gzip:guesses.txt.gz, "-" is stdout and spec without known kind is plain file.
+// Files are split after every rotate guesses if rotate is greater than 0.
+func Open(spec string, rotate uint64) (io.WriteCloser, error) {
+	if spec == "" || spec == "-" {
+		if rotate > 0 {
+			return nil, ErrRotate
+		}
+		return stdoutSink{}, nil
+	}
+	kind, target := "file", spec
+	if i := strings.Index(spec, ":"); i > 0 && kinds[spec[:i]] {
+		kind, target = spec[:i], spec[i+1:]
+	}
+	var open func(path string) (io.WriteCloser, error)
+	switch kind {
+	case "file":
+		open = openFile
+	case "gzip":
+		open = openGzip
+	case "zstd":
+		open = openZstd
+	case "fifo":
+		if rotate > 0 {
+			return nil, ErrRotate
+		}
+		return openFifo(target)
+	case "tcp":
+		if rotate > 0 {
+			return nil, ErrRotate
+		}
+		return net.Dial("tcp", target)
+	}
+	if rotate == 0 {
+		return open(target)
+	}
+	r := &rotatingSink{
+		every: rotate,
+		open: func(index int) (io.WriteCloser, error) {
+			return open(rotatedName(target, index))
+		},
+	}
+	if err := r.rotate(); err != nil {
+		return nil, err
+	}
+	return r, nil
+}
+
+func openFile(path string) (io.WriteCloser, error) {
+	return os.Create(path)
+}
+
+func openGzip(path string) (io.WriteCloser, error) {
+	f, err := os.Create(path)
+	if err != nil {
+		return nil, err
+	}
+	return &compressedSink{WriteCloser: gzip.NewWriter(f), file: f}, nil
+}
+
+func openZstd(path string) (io.WriteCloser, error) {
+	f, err := os.Create(path)
+	if err != nil {
+		return nil, err
+	}
+	w, err := zstd.NewWriter(f)
+	if err != nil {
+		f.Close()
+		return nil, err
+	}
+	return &compressedSink{WriteCloser: w, file: f}, nil
+}
+
+// rotatedName inserts index before extension, first file is guesses.txt.gz => guesses.0000.txt.gz
+func rotatedName(path string, index int) string {
+	dir, name := filepath.Split(path)
+	if i := strings.Index(name, "."); i > 0 {
+		return dir + fmt.Sprintf("%s.%04d%s", name[:i], index, name[i:])
+	}
+	return dir + fmt.Sprintf("%s.%04d", name, index)
+}
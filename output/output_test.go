@@ -0,0 +1,180 @@
+package output
+
+import (
+	"bytes"
+	"compress/gzip"
+	"github.com/klauspost/compress/zstd"
+	"io"
+	"io/ioutil"
+	"net"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+const guesses = "pass\nlove\npass12\nFISH12\nab\n"
+
+func readGzip(t *testing.T, path string) []byte {
+	t.Helper()
+	f, err := os.Open(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer f.Close()
+	r, err := gzip.NewReader(f)
+	if err != nil {
+		t.Fatal(err)
+	}
+	data, err := ioutil.ReadAll(r)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return data
+}
+
+func readZstd(t *testing.T, path string) []byte {
+	t.Helper()
+	f, err := os.Open(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer f.Close()
+	r, err := zstd.NewReader(f)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer r.Close()
+	data, err := ioutil.ReadAll(r)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return data
+}
+
+func readFile(t *testing.T, path string) []byte {
+	t.Helper()
+	data, err := ioutil.ReadFile(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return data
+}
+
+func TestOpen(t *testing.T) {
+	dir := t.TempDir()
+	tests := []struct {
+		name string
+		spec string
+		// path is file which contains output
+		path string
+		read func(t *testing.T, path string) []byte
+	}{
+		{"file", "file:" + filepath.Join(dir, "guesses.txt"), filepath.Join(dir, "guesses.txt"), readFile},
+		{"gzip", "gzip:" + filepath.Join(dir, "guesses.txt.gz"), filepath.Join(dir, "guesses.txt.gz"), readGzip},
+		{"zstd", "zstd:" + filepath.Join(dir, "guesses.txt.zst"), filepath.Join(dir, "guesses.txt.zst"), readZstd},
+		{"path", filepath.Join(dir, "plain.txt"), filepath.Join(dir, "plain.txt"), readFile},
+		// prefix isn't kind of sink, so whole spec is path
+		{"unknown kind", filepath.Join(dir, "out:1.txt"), filepath.Join(dir, "out:1.txt"), readFile},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			w, err := Open(tt.spec, 0)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if _, err := io.WriteString(w, guesses); err != nil {
+				t.Fatal(err)
+			}
+			if err := w.Close(); err != nil {
+				t.Fatal(err)
+			}
+			if got := tt.read(t, tt.path); string(got) != guesses {
+				t.Errorf("%s contains %q, want %q", tt.path, got, guesses)
+			}
+		})
+	}
+}
+
+func TestOpenTCP(t *testing.T) {
+	l, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer l.Close()
+	received := make(chan []byte, 1)
+	go func() {
+		conn, err := l.Accept()
+		if err != nil {
+			received <- nil
+			return
+		}
+		defer conn.Close()
+		data, _ := ioutil.ReadAll(conn)
+		received <- data
+	}()
+	w, err := Open("tcp:"+l.Addr().String(), 0)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := io.WriteString(w, guesses); err != nil {
+		t.Fatal(err)
+	}
+	if err := w.Close(); err != nil {
+		t.Fatal(err)
+	}
+	if got := <-received; string(got) != guesses {
+		t.Errorf("received %q, want %q", got, guesses)
+	}
+	if _, err := Open("tcp:"+l.Addr().String(), 2); err != ErrRotate {
+		t.Errorf("Open of tcp sink with rotation = %v, want %v", err, ErrRotate)
+	}
+	if _, err := Open("-", 2); err != ErrRotate {
+		t.Errorf("Open of stdout with rotation = %v, want %v", err, ErrRotate)
+	}
+}
+
+func TestRotatingSink(t *testing.T) {
+	tests := []struct {
+		name  string
+		kind  string
+		file  string
+		files []string
+		read  func(t *testing.T, path string) []byte
+	}{
+		{"file", "", "guesses.txt", []string{"guesses.0000.txt", "guesses.0001.txt", "guesses.0002.txt"}, readFile},
+		{"gzip", "gzip:", "guesses.txt.gz", []string{"guesses.0000.txt.gz", "guesses.0001.txt.gz", "guesses.0002.txt.gz"}, readGzip},
+		{"no extension", "", "guesses", []string{"guesses.0000", "guesses.0001", "guesses.0002"}, readFile},
+	}
+	want := []string{"pass\nlove\n", "pass12\nFISH12\n", "ab\n"}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			dir := t.TempDir()
+			w, err := Open(tt.kind+filepath.Join(dir, tt.file), 2)
+			if err != nil {
+				t.Fatal(err)
+			}
+			// lines are split across writes, file is switched only on end of line
+			for _, chunk := range []string{"pass\nlo", "ve\npass12\nFI", "SH12\nab\n"} {
+				if _, err := io.WriteString(w, chunk); err != nil {
+					t.Fatal(err)
+				}
+			}
+			if err := w.Close(); err != nil {
+				t.Fatal(err)
+			}
+			for i, name := range tt.files {
+				if got := tt.read(t, filepath.Join(dir, name)); !bytes.Equal(got, []byte(want[i])) {
+					t.Errorf("%s contains %q, want %q", name, got, want[i])
+				}
+			}
+			// next file is opened only when there is next guess
+			files, err := ioutil.ReadDir(dir)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if len(files) != len(tt.files) {
+				t.Errorf("%d files were created, want %d", len(files), len(tt.files))
+			}
+		})
+	}
+}
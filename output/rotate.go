@@ -0,0 +1,72 @@
+package output
+
+import (
+	"bytes"
+	"io"
+)
+
+// rotatingSink opens new file after every guesses, files are split only on end of line
+type rotatingSink struct {
+	open  func(index int) (io.WriteCloser, error)
+	every uint64
+	lines uint64
+	index int
+	cur   io.WriteCloser
+}
+
+func (r *rotatingSink) rotate() error {
+	if r.cur != nil {
+		if err := r.cur.Close(); err != nil {
+			return err
+		}
+	}
+	w, err := r.open(r.index)
+	if err != nil {
+		r.cur = nil
+		return err
+	}
+	r.index++
+	r.lines = 0
+	r.cur = w
+	return nil
+}
+
+func (r *rotatingSink) Write(p []byte) (int, error) {
+	written := 0
+	for len(p) > 0 {
+		if r.lines == r.every {
+			if err := r.rotate(); err != nil {
+				return written, err
+			}
+		}
+		// find end of line, which completes current file
+		end := len(p)
+		lines := r.lines
+		for pos := 0; lines < r.every; {
+			idx := bytes.IndexByte(p[pos:], '\n')
+			if idx < 0 {
+				break
+			}
+			pos += idx + 1
+			lines++
+			if lines == r.every {
+				end = pos
+			}
+		}
+		n, err := r.cur.Write(p[:end])
+		written += n
+		if err != nil {
+			return written, err
+		}
+		r.lines = lines
+		p = p[end:]
+	}
+	return written, nil
+}
+
+func (r *rotatingSink) Close() error {
+	if r.cur == nil {
+		return nil
+	}
+	return r.cur.Close()
+}
@@ -225,10 +225,77 @@ func (m *CrackingResponse) GetHashes() map[string]string {
 	return nil
 }
 
+// status of cracker sent by client periodically
+type Progress struct {
+	// measured speed in guesses per second
+	Speed float64 `protobuf:"fixed64,1,opt,name=speed,proto3" json:"speed,omitempty"`
+	// guesses processed by cracker in session
+	Guesses              uint64   `protobuf:"varint,2,opt,name=guesses,proto3" json:"guesses,omitempty"`
+	RecoveredHashes      uint64   `protobuf:"varint,3,opt,name=recoveredHashes,proto3" json:"recoveredHashes,omitempty"`
+	TotalHashes          uint64   `protobuf:"varint,4,opt,name=totalHashes,proto3" json:"totalHashes,omitempty"`
+	XXX_NoUnkeyedLiteral struct{} `json:"-"`
+	XXX_unrecognized     []byte   `json:"-"`
+	XXX_sizecache        int32    `json:"-"`
+}
+
+func (m *Progress) Reset()         { *m = Progress{} }
+func (m *Progress) String() string { return proto.CompactTextString(m) }
+func (*Progress) ProtoMessage()    {}
+func (*Progress) Descriptor() ([]byte, []int) {
+	return fileDescriptor_2fcc84b9998d60d8, []int{5}
+}
+
+func (m *Progress) XXX_Unmarshal(b []byte) error {
+	return xxx_messageInfo_Progress.Unmarshal(m, b)
+}
+func (m *Progress) XXX_Marshal(b []byte, deterministic bool) ([]byte, error) {
+	return xxx_messageInfo_Progress.Marshal(b, m, deterministic)
+}
+func (m *Progress) XXX_Merge(src proto.Message) {
+	xxx_messageInfo_Progress.Merge(m, src)
+}
+func (m *Progress) XXX_Size() int {
+	return xxx_messageInfo_Progress.Size(m)
+}
+func (m *Progress) XXX_DiscardUnknown() {
+	xxx_messageInfo_Progress.DiscardUnknown(m)
+}
+
+var xxx_messageInfo_Progress proto.InternalMessageInfo
+
+func (m *Progress) GetSpeed() float64 {
+	if m != nil {
+		return m.Speed
+	}
+	return 0
+}
+
+func (m *Progress) GetGuesses() uint64 {
+	if m != nil {
+		return m.Guesses
+	}
+	return 0
+}
+
+func (m *Progress) GetRecoveredHashes() uint64 {
+	if m != nil {
+		return m.RecoveredHashes
+	}
+	return 0
+}
+
+func (m *Progress) GetTotalHashes() uint64 {
+	if m != nil {
+		return m.TotalHashes
+	}
+	return 0
+}
+
 type Grammar struct {
 	RulesFolder          string             `protobuf:"bytes,1,opt,name=rulesFolder,proto3" json:"rulesFolder,omitempty"`
 	Sections             []*Section         `protobuf:"bytes,2,rep,name=sections,proto3" json:"sections,omitempty"`
 	Mapping              map[string]*IntMap `protobuf:"bytes,3,rep,name=mapping,proto3" json:"mapping,omitempty" protobuf_key:"bytes,1,opt,name=key,proto3" protobuf_val:"bytes,2,opt,name=value,proto3"`
+	Omen                 *Omen              `protobuf:"bytes,4,opt,name=omen,proto3" json:"omen,omitempty"`
 	XXX_NoUnkeyedLiteral struct{}           `json:"-"`
 	XXX_unrecognized     []byte             `json:"-"`
 	XXX_sizecache        int32              `json:"-"`
@@ -238,7 +305,7 @@ func (m *Grammar) Reset()         { *m = Grammar{} }
 func (m *Grammar) String() string { return proto.CompactTextString(m) }
 func (*Grammar) ProtoMessage()    {}
 func (*Grammar) Descriptor() ([]byte, []int) {
-	return fileDescriptor_2fcc84b9998d60d8, []int{5}
+	return fileDescriptor_2fcc84b9998d60d8, []int{6}
 }
 
 func (m *Grammar) XXX_Unmarshal(b []byte) error {
@@ -280,6 +347,92 @@ func (m *Grammar) GetMapping() map[string]*IntMap {
 	return nil
 }
 
+func (m *Grammar) GetOmen() *Omen {
+	if m != nil {
+		return m.Omen
+	}
+	return nil
+}
+
+type Omen struct {
+	Ngram                int32            `protobuf:"varint,1,opt,name=ngram,proto3" json:"ngram,omitempty"`
+	MaxLevel             int32            `protobuf:"varint,2,opt,name=maxLevel,proto3" json:"maxLevel,omitempty"`
+	Ip                   map[string]int32 `protobuf:"bytes,3,rep,name=ip,proto3" json:"ip,omitempty" protobuf_key:"bytes,1,opt,name=key,proto3" protobuf_val:"varint,2,opt,name=value,proto3"`
+	Cp                   map[string]int32 `protobuf:"bytes,4,rep,name=cp,proto3" json:"cp,omitempty" protobuf_key:"bytes,1,opt,name=key,proto3" protobuf_val:"varint,2,opt,name=value,proto3"`
+	Ep                   map[string]int32 `protobuf:"bytes,5,rep,name=ep,proto3" json:"ep,omitempty" protobuf_key:"bytes,1,opt,name=key,proto3" protobuf_val:"varint,2,opt,name=value,proto3"`
+	Ln                   []int32          `protobuf:"varint,6,rep,packed,name=ln,proto3" json:"ln,omitempty"`
+	XXX_NoUnkeyedLiteral struct{}         `json:"-"`
+	XXX_unrecognized     []byte           `json:"-"`
+	XXX_sizecache        int32            `json:"-"`
+}
+
+func (m *Omen) Reset()         { *m = Omen{} }
+func (m *Omen) String() string { return proto.CompactTextString(m) }
+func (*Omen) ProtoMessage()    {}
+func (*Omen) Descriptor() ([]byte, []int) {
+	return fileDescriptor_2fcc84b9998d60d8, []int{7}
+}
+
+func (m *Omen) XXX_Unmarshal(b []byte) error {
+	return xxx_messageInfo_Omen.Unmarshal(m, b)
+}
+func (m *Omen) XXX_Marshal(b []byte, deterministic bool) ([]byte, error) {
+	return xxx_messageInfo_Omen.Marshal(b, m, deterministic)
+}
+func (m *Omen) XXX_Merge(src proto.Message) {
+	xxx_messageInfo_Omen.Merge(m, src)
+}
+func (m *Omen) XXX_Size() int {
+	return xxx_messageInfo_Omen.Size(m)
+}
+func (m *Omen) XXX_DiscardUnknown() {
+	xxx_messageInfo_Omen.DiscardUnknown(m)
+}
+
+var xxx_messageInfo_Omen proto.InternalMessageInfo
+
+func (m *Omen) GetNgram() int32 {
+	if m != nil {
+		return m.Ngram
+	}
+	return 0
+}
+
+func (m *Omen) GetMaxLevel() int32 {
+	if m != nil {
+		return m.MaxLevel
+	}
+	return 0
+}
+
+func (m *Omen) GetIp() map[string]int32 {
+	if m != nil {
+		return m.Ip
+	}
+	return nil
+}
+
+func (m *Omen) GetCp() map[string]int32 {
+	if m != nil {
+		return m.Cp
+	}
+	return nil
+}
+
+func (m *Omen) GetEp() map[string]int32 {
+	if m != nil {
+		return m.Ep
+	}
+	return nil
+}
+
+func (m *Omen) GetLn() []int32 {
+	if m != nil {
+		return m.Ln
+	}
+	return nil
+}
+
 type IntMap struct {
 	Value                map[string]int32 `protobuf:"bytes,1,rep,name=value,proto3" json:"value,omitempty" protobuf_key:"bytes,1,opt,name=key,proto3" protobuf_val:"varint,2,opt,name=value,proto3"`
 	XXX_NoUnkeyedLiteral struct{}         `json:"-"`
@@ -291,7 +444,7 @@ func (m *IntMap) Reset()         { *m = IntMap{} }
 func (m *IntMap) String() string { return proto.CompactTextString(m) }
 func (*IntMap) ProtoMessage()    {}
 func (*IntMap) Descriptor() ([]byte, []int) {
-	return fileDescriptor_2fcc84b9998d60d8, []int{6}
+	return fileDescriptor_2fcc84b9998d60d8, []int{8}
 }
 
 func (m *IntMap) XXX_Unmarshal(b []byte) error {
@@ -334,7 +487,7 @@ func (m *Replacement) Reset()         { *m = Replacement{} }
 func (m *Replacement) String() string { return proto.CompactTextString(m) }
 func (*Replacement) ProtoMessage()    {}
 func (*Replacement) Descriptor() ([]byte, []int) {
-	return fileDescriptor_2fcc84b9998d60d8, []int{7}
+	return fileDescriptor_2fcc84b9998d60d8, []int{9}
 }
 
 func (m *Replacement) XXX_Unmarshal(b []byte) error {
@@ -403,7 +556,7 @@ func (m *Section) Reset()         { *m = Section{} }
 func (m *Section) String() string { return proto.CompactTextString(m) }
 func (*Section) ProtoMessage()    {}
 func (*Section) Descriptor() ([]byte, []int) {
-	return fileDescriptor_2fcc84b9998d60d8, []int{8}
+	return fileDescriptor_2fcc84b9998d60d8, []int{10}
 }
 
 func (m *Section) XXX_Unmarshal(b []byte) error {
@@ -458,7 +611,7 @@ func (m *Items) Reset()         { *m = Items{} }
 func (m *Items) String() string { return proto.CompactTextString(m) }
 func (*Items) ProtoMessage()    {}
 func (*Items) Descriptor() ([]byte, []int) {
-	return fileDescriptor_2fcc84b9998d60d8, []int{9}
+	return fileDescriptor_2fcc84b9998d60d8, []int{11}
 }
 
 func (m *Items) XXX_Unmarshal(b []byte) error {
@@ -514,7 +667,7 @@ func (m *TreeItem) Reset()         { *m = TreeItem{} }
 func (m *TreeItem) String() string { return proto.CompactTextString(m) }
 func (*TreeItem) ProtoMessage()    {}
 func (*TreeItem) Descriptor() ([]byte, []int) {
-	return fileDescriptor_2fcc84b9998d60d8, []int{10}
+	return fileDescriptor_2fcc84b9998d60d8, []int{12}
 }
 
 func (m *TreeItem) XXX_Unmarshal(b []byte) error {
@@ -563,6 +716,141 @@ func (m *TreeItem) GetId() bool {
 	return false
 }
 
+type QueueItem struct {
+	IsTerminal           bool      `protobuf:"varint,1,opt,name=isTerminal,proto3" json:"isTerminal,omitempty"`
+	Probability          float64   `protobuf:"fixed64,2,opt,name=probability,proto3" json:"probability,omitempty"`
+	Tree                 *TreeItem `protobuf:"bytes,3,opt,name=tree,proto3" json:"tree,omitempty"`
+	XXX_NoUnkeyedLiteral struct{}  `json:"-"`
+	XXX_unrecognized     []byte    `json:"-"`
+	XXX_sizecache        int32     `json:"-"`
+}
+
+func (m *QueueItem) Reset()         { *m = QueueItem{} }
+func (m *QueueItem) String() string { return proto.CompactTextString(m) }
+func (*QueueItem) ProtoMessage()    {}
+func (*QueueItem) Descriptor() ([]byte, []int) {
+	return fileDescriptor_2fcc84b9998d60d8, []int{13}
+}
+
+func (m *QueueItem) XXX_Unmarshal(b []byte) error {
+	return xxx_messageInfo_QueueItem.Unmarshal(m, b)
+}
+func (m *QueueItem) XXX_Marshal(b []byte, deterministic bool) ([]byte, error) {
+	return xxx_messageInfo_QueueItem.Marshal(b, m, deterministic)
+}
+func (m *QueueItem) XXX_Merge(src proto.Message) {
+	xxx_messageInfo_QueueItem.Merge(m, src)
+}
+func (m *QueueItem) XXX_Size() int {
+	return xxx_messageInfo_QueueItem.Size(m)
+}
+func (m *QueueItem) XXX_DiscardUnknown() {
+	xxx_messageInfo_QueueItem.DiscardUnknown(m)
+}
+
+var xxx_messageInfo_QueueItem proto.InternalMessageInfo
+
+func (m *QueueItem) GetIsTerminal() bool {
+	if m != nil {
+		return m.IsTerminal
+	}
+	return false
+}
+
+func (m *QueueItem) GetProbability() float64 {
+	if m != nil {
+		return m.Probability
+	}
+	return 0
+}
+
+func (m *QueueItem) GetTree() *TreeItem {
+	if m != nil {
+		return m.Tree
+	}
+	return nil
+}
+
+type Checkpoint struct {
+	Queue     []*QueueItem `protobuf:"bytes,1,rep,name=queue,proto3" json:"queue,omitempty"`
+	Pending   []*TreeItem  `protobuf:"bytes,2,rep,name=pending,proto3" json:"pending,omitempty"`
+	MaxProb   float64      `protobuf:"fixed64,3,opt,name=maxProb,proto3" json:"maxProb,omitempty"`
+	Generated uint64       `protobuf:"varint,4,opt,name=generated,proto3" json:"generated,omitempty"`
+	MinProb   float64      `protobuf:"fixed64,5,opt,name=minProb,proto3" json:"minProb,omitempty"`
+	// sum of probabilities of generated guesses
+	Covered              float64  `protobuf:"fixed64,6,opt,name=covered,proto3" json:"covered,omitempty"`
+	XXX_NoUnkeyedLiteral struct{} `json:"-"`
+	XXX_unrecognized     []byte   `json:"-"`
+	XXX_sizecache        int32    `json:"-"`
+}
+
+func (m *Checkpoint) Reset()         { *m = Checkpoint{} }
+func (m *Checkpoint) String() string { return proto.CompactTextString(m) }
+func (*Checkpoint) ProtoMessage()    {}
+func (*Checkpoint) Descriptor() ([]byte, []int) {
+	return fileDescriptor_2fcc84b9998d60d8, []int{14}
+}
+
+func (m *Checkpoint) XXX_Unmarshal(b []byte) error {
+	return xxx_messageInfo_Checkpoint.Unmarshal(m, b)
+}
+func (m *Checkpoint) XXX_Marshal(b []byte, deterministic bool) ([]byte, error) {
+	return xxx_messageInfo_Checkpoint.Marshal(b, m, deterministic)
+}
+func (m *Checkpoint) XXX_Merge(src proto.Message) {
+	xxx_messageInfo_Checkpoint.Merge(m, src)
+}
+func (m *Checkpoint) XXX_Size() int {
+	return xxx_messageInfo_Checkpoint.Size(m)
+}
+func (m *Checkpoint) XXX_DiscardUnknown() {
+	xxx_messageInfo_Checkpoint.DiscardUnknown(m)
+}
+
+var xxx_messageInfo_Checkpoint proto.InternalMessageInfo
+
+func (m *Checkpoint) GetQueue() []*QueueItem {
+	if m != nil {
+		return m.Queue
+	}
+	return nil
+}
+
+func (m *Checkpoint) GetPending() []*TreeItem {
+	if m != nil {
+		return m.Pending
+	}
+	return nil
+}
+
+func (m *Checkpoint) GetMaxProb() float64 {
+	if m != nil {
+		return m.MaxProb
+	}
+	return 0
+}
+
+func (m *Checkpoint) GetGenerated() uint64 {
+	if m != nil {
+		return m.Generated
+	}
+	return 0
+}
+
+func (m *Checkpoint) GetMinProb() float64 {
+	if m != nil {
+		return m.MinProb
+	}
+	return 0
+}
+
+func (m *Checkpoint) GetCovered() float64 {
+	if m != nil {
+		return m.Covered
+	}
+	return 0
+}
+
 func init() {
 	proto.RegisterType((*Empty)(nil), "proto.Empty")
 	proto.RegisterType((*NextRequest)(nil), "proto.NextRequest")
@@ -570,64 +858,90 @@ func init() {
 	proto.RegisterType((*ResultResponse)(nil), "proto.ResultResponse")
 	proto.RegisterType((*CrackingResponse)(nil), "proto.CrackingResponse")
 	proto.RegisterMapType((map[string]string)(nil), "proto.CrackingResponse.HashesEntry")
+	proto.RegisterType((*Progress)(nil), "proto.Progress")
 	proto.RegisterType((*Grammar)(nil), "proto.Grammar")
 	proto.RegisterMapType((map[string]*IntMap)(nil), "proto.Grammar.MappingEntry")
+	proto.RegisterType((*Omen)(nil), "proto.Omen")
+	proto.RegisterMapType((map[string]int32)(nil), "proto.Omen.CpEntry")
+	proto.RegisterMapType((map[string]int32)(nil), "proto.Omen.EpEntry")
+	proto.RegisterMapType((map[string]int32)(nil), "proto.Omen.IpEntry")
 	proto.RegisterType((*IntMap)(nil), "proto.IntMap")
 	proto.RegisterMapType((map[string]int32)(nil), "proto.IntMap.ValueEntry")
 	proto.RegisterType((*Replacement)(nil), "proto.Replacement")
 	proto.RegisterType((*Section)(nil), "proto.Section")
 	proto.RegisterType((*Items)(nil), "proto.Items")
 	proto.RegisterType((*TreeItem)(nil), "proto.TreeItem")
+	proto.RegisterType((*QueueItem)(nil), "proto.QueueItem")
+	proto.RegisterType((*Checkpoint)(nil), "proto.Checkpoint")
 }
 
 func init() { proto.RegisterFile("proto.proto", fileDescriptor_2fcc84b9998d60d8) }
 
 var fileDescriptor_2fcc84b9998d60d8 = []byte{
-	// 692 bytes of a gzipped FileDescriptorProto
-	0x1f, 0x8b, 0x08, 0x00, 0x00, 0x00, 0x00, 0x00, 0x02, 0xff, 0x84, 0x54, 0x4b, 0x6f, 0xd3, 0x4a,
-	0x14, 0xae, 0x93, 0x38, 0x8f, 0xe3, 0xdc, 0xb4, 0x1a, 0xdd, 0xdb, 0x6b, 0x05, 0x84, 0x22, 0x17,
-	0xa1, 0x08, 0x44, 0x24, 0x52, 0x81, 0xca, 0x63, 0x17, 0xda, 0x52, 0x41, 0x11, 0x9a, 0x56, 0xec,
-	0xdd, 0xf8, 0xd0, 0x8e, 0x6a, 0x8f, 0xcd, 0xcc, 0x04, 0x35, 0x2b, 0x24, 0x96, 0xec, 0xf9, 0x71,
-	0xfc, 0x16, 0x36, 0x68, 0x1e, 0x4e, 0xec, 0x52, 0x60, 0x93, 0xcc, 0xf9, 0xe6, 0x3b, 0xaf, 0xef,
-	0x78, 0x0e, 0x04, 0x85, 0xc8, 0x55, 0x3e, 0x31, 0xbf, 0xc4, 0x37, 0x7f, 0x51, 0x07, 0xfc, 0xfd,
-	0xac, 0x50, 0xcb, 0xe8, 0x01, 0x04, 0x6f, 0xf1, 0x4a, 0x51, 0xfc, 0xb8, 0x40, 0xa9, 0xc8, 0x6d,
-	0xe8, 0x29, 0x14, 0x19, 0xe3, 0x71, 0x2a, 0x43, 0x6f, 0xe4, 0x8d, 0x5b, 0x74, 0x0d, 0x44, 0x4b,
-	0xd8, 0x9c, 0xe5, 0x9c, 0xe3, 0x5c, 0x51, 0x94, 0x45, 0xce, 0x25, 0x92, 0x31, 0x74, 0xce, 0x45,
-	0x9c, 0x65, 0xb1, 0x30, 0xf4, 0x60, 0x3a, 0xb0, 0x89, 0x26, 0x87, 0x16, 0xa5, 0xe5, 0x35, 0x19,
-	0x42, 0xf7, 0x22, 0x96, 0x17, 0x6f, 0x98, 0x54, 0x61, 0x63, 0xd4, 0x1c, 0xf7, 0xe8, 0xca, 0x26,
-	0x23, 0x08, 0xf4, 0x79, 0x1e, 0xab, 0xe3, 0x3c, 0xc1, 0xb0, 0x39, 0xf2, 0xc6, 0x3d, 0x5a, 0x85,
-	0xa2, 0x08, 0x06, 0x14, 0xe5, 0x22, 0x5d, 0x67, 0xde, 0x82, 0x26, 0xf2, 0xc4, 0x64, 0xed, 0x52,
-	0x7d, 0x8c, 0xbe, 0x7a, 0xb0, 0x35, 0x13, 0xf1, 0xfc, 0x92, 0xf1, 0xf3, 0x15, 0xed, 0x39, 0xb4,
-	0x75, 0x1c, 0xd4, 0xed, 0x34, 0xc7, 0xc1, 0x74, 0xc7, 0xd5, 0x77, 0x9d, 0x38, 0x79, 0x65, 0x58,
-	0xfb, 0x5c, 0x89, 0x25, 0x75, 0x2e, 0xc3, 0xa7, 0x10, 0x54, 0x60, 0x9d, 0xf2, 0x12, 0x97, 0x26,
-	0x65, 0x8f, 0xea, 0x23, 0xf9, 0x17, 0xfc, 0x4f, 0x71, 0xba, 0xc0, 0xb0, 0x61, 0x30, 0x6b, 0x3c,
-	0x6b, 0xec, 0x79, 0xd1, 0x77, 0x0f, 0x3a, 0x4e, 0x03, 0xdd, 0x9e, 0x58, 0xa4, 0x28, 0x0f, 0xf2,
-	0x34, 0x41, 0xe1, 0xfc, 0xab, 0x10, 0xb9, 0x0f, 0x5d, 0x89, 0x73, 0xc5, 0x72, 0x2e, 0x8d, 0x38,
-	0x6b, 0x1d, 0x4f, 0x2c, 0x4c, 0x57, 0xf7, 0xe4, 0x31, 0x74, 0xb2, 0xb8, 0x28, 0x18, 0x3f, 0x0f,
-	0x9b, 0x86, 0x7a, 0xab, 0x2e, 0xf9, 0xe4, 0xd8, 0xde, 0xda, 0x56, 0x4a, 0xee, 0xf0, 0x08, 0xfa,
-	0xd5, 0x8b, 0x1b, 0x9a, 0xd9, 0xa9, 0x36, 0x13, 0x4c, 0xff, 0x71, 0x61, 0x8f, 0xb8, 0x3a, 0x8e,
-	0x8b, 0x6a, 0x6f, 0x02, 0xda, 0x16, 0x24, 0x93, 0xd2, 0xc5, 0x8a, 0x1b, 0xd6, 0x5c, 0x26, 0xef,
-	0xf5, 0x95, 0x2d, 0xc3, 0xd2, 0x86, 0x7b, 0x00, 0x6b, 0xf0, 0x6f, 0x7a, 0xfa, 0xd5, 0x9c, 0xdf,
-	0x3c, 0x08, 0x28, 0x16, 0x69, 0x3c, 0xc7, 0x0c, 0xb9, 0xf9, 0x64, 0x0a, 0x91, 0x9f, 0xc5, 0x67,
-	0x2c, 0x65, 0xca, 0xc6, 0xf0, 0x68, 0x15, 0x22, 0x77, 0x00, 0x98, 0x3c, 0x75, 0x1f, 0xaf, 0x09,
-	0xd8, 0xa5, 0x15, 0x84, 0x6c, 0x43, 0xdb, 0x84, 0x97, 0x46, 0xc6, 0x1e, 0x75, 0x96, 0xfe, 0x50,
-	0x3f, 0x2c, 0xb8, 0x11, 0x3b, 0x6c, 0x99, 0xd2, 0x56, 0xb6, 0xae, 0xb8, 0xc8, 0x65, 0xe8, 0x8f,
-	0x9a, 0x63, 0x9f, 0xea, 0x63, 0xc4, 0xa0, 0xe3, 0x46, 0x44, 0x08, 0xb4, 0xd4, 0xb2, 0x40, 0xd7,
-	0x8f, 0x39, 0x6b, 0x8c, 0xc7, 0x59, 0xf9, 0x7d, 0x98, 0x33, 0x79, 0x02, 0x7d, 0xb1, 0xee, 0x44,
-	0xba, 0x29, 0x12, 0xa7, 0x5d, 0xa5, 0x49, 0x5a, 0xe3, 0x45, 0x5f, 0x3c, 0xf0, 0x8f, 0x14, 0x66,
-	0x92, 0xec, 0x42, 0xbf, 0x10, 0x78, 0x5a, 0x79, 0xa9, 0x3a, 0xc2, 0xa6, 0x8b, 0x70, 0x2a, 0x10,
-	0x35, 0x8f, 0xd6, 0x48, 0xf5, 0xb7, 0x6d, 0x5f, 0xe0, 0x1a, 0x20, 0xf7, 0x60, 0xb0, 0x32, 0x66,
-	0xf9, 0x82, 0x2b, 0xf3, 0x0a, 0x5b, 0xf4, 0x1a, 0x1a, 0x7d, 0x86, 0x6e, 0x19, 0x5f, 0x4f, 0x8b,
-	0xf1, 0x04, 0xaf, 0x4c, 0xc7, 0x3e, 0xb5, 0x86, 0xd6, 0x5d, 0x89, 0x98, 0x4b, 0x66, 0x14, 0xb4,
-	0x83, 0xac, 0x20, 0xe4, 0x21, 0xf4, 0xe6, 0x17, 0x2c, 0x4d, 0x04, 0xf2, 0xb2, 0xf7, 0x5f, 0x2a,
-	0x5f, 0x33, 0xc8, 0x00, 0x1a, 0x2c, 0x31, 0x83, 0xe8, 0xd2, 0x06, 0x4b, 0xa6, 0x3f, 0x3c, 0x68,
-	0xbd, 0x9b, 0x1d, 0x1c, 0x92, 0x47, 0xd0, 0x71, 0xdb, 0x88, 0xf4, 0x9d, 0xbf, 0xd9, 0x69, 0xc3,
-	0xed, 0xf2, 0x89, 0xd7, 0x77, 0x55, 0xb4, 0x41, 0xc6, 0x00, 0x2f, 0x99, 0x9c, 0xdf, 0xe8, 0x55,
-	0xb3, 0xc8, 0x14, 0xfa, 0x87, 0xa8, 0xf4, 0x6a, 0xb4, 0x8a, 0x97, 0xd3, 0xa9, 0x2c, 0xcb, 0x95,
-	0x87, 0x61, 0x44, 0x1b, 0xe4, 0x05, 0xc0, 0x09, 0xf2, 0xc4, 0xee, 0x29, 0xf2, 0xff, 0x6f, 0x16,
-	0xcd, 0xf0, 0xbf, 0xd5, 0xa0, 0x6b, 0xfb, 0xec, 0x2e, 0xb4, 0x5e, 0xb3, 0x34, 0xfd, 0x53, 0x55,
-	0xd1, 0xc6, 0x59, 0xdb, 0x98, 0xbb, 0x3f, 0x03, 0x00, 0x00, 0xff, 0xff, 0x3f, 0xfd, 0xe5, 0x86,
-	0xd5, 0x05, 0x00, 0x00,
+	// 996 bytes of a gzipped FileDescriptorProto
+	0x1f, 0x8b, 0x08, 0x00, 0x00, 0x00, 0x00, 0x00, 0x02, 0xff, 0x9c, 0x56, 0xdd, 0x6e, 0xe3, 0x44,
+	0x14, 0xae, 0x1d, 0x3b, 0x3f, 0x27, 0xa1, 0xad, 0x06, 0x58, 0xac, 0x80, 0xa0, 0x72, 0xd1, 0x2a,
+	0x80, 0x08, 0x22, 0xab, 0x45, 0xcb, 0xcf, 0x5d, 0xe8, 0x96, 0x8a, 0x2d, 0x94, 0xd9, 0x8a, 0x7b,
+	0xd7, 0x3e, 0xa4, 0x56, 0xed, 0xf1, 0xec, 0xcc, 0xa4, 0x6a, 0xae, 0x90, 0xb8, 0xe0, 0x82, 0x7b,
+	0xde, 0x82, 0x37, 0xe1, 0x15, 0x78, 0x12, 0xae, 0xd0, 0xfc, 0xd8, 0xb1, 0xdb, 0x2e, 0xd2, 0xee,
+	0x4d, 0x32, 0xe7, 0x3b, 0xdf, 0x39, 0x73, 0xfe, 0x66, 0xc6, 0x30, 0xe6, 0xa2, 0x52, 0xd5, 0xdc,
+	0xfc, 0x92, 0xd0, 0xfc, 0xc5, 0x03, 0x08, 0x8f, 0x4a, 0xae, 0x36, 0xf1, 0x27, 0x30, 0xfe, 0x01,
+	0x6f, 0x14, 0xc5, 0x17, 0x6b, 0x94, 0x8a, 0xbc, 0x07, 0x23, 0x85, 0xa2, 0xcc, 0x59, 0x52, 0xc8,
+	0xc8, 0x3b, 0xf0, 0x66, 0x01, 0xdd, 0x02, 0xf1, 0x06, 0xf6, 0x96, 0x15, 0x63, 0x98, 0x2a, 0x8a,
+	0x92, 0x57, 0x4c, 0x22, 0x99, 0xc1, 0x60, 0x25, 0x92, 0xb2, 0x4c, 0x84, 0xa1, 0x8f, 0x17, 0xbb,
+	0x76, 0xa3, 0xf9, 0xb1, 0x45, 0x69, 0xad, 0x26, 0x53, 0x18, 0x5e, 0x26, 0xf2, 0xf2, 0x59, 0x2e,
+	0x55, 0xe4, 0x1f, 0xf4, 0x66, 0x23, 0xda, 0xc8, 0xe4, 0x00, 0xc6, 0x7a, 0x9d, 0x26, 0xea, 0xb4,
+	0xca, 0x30, 0xea, 0x1d, 0x78, 0xb3, 0x11, 0x6d, 0x43, 0x71, 0x0c, 0xbb, 0x14, 0xe5, 0xba, 0xd8,
+	0xee, 0xbc, 0x0f, 0x3d, 0x64, 0x99, 0xd9, 0x75, 0x48, 0xf5, 0x32, 0xfe, 0xc3, 0x83, 0xfd, 0xa5,
+	0x48, 0xd2, 0xab, 0x9c, 0xad, 0x1a, 0xda, 0xd7, 0xd0, 0xd7, 0x7e, 0x50, 0xa7, 0xd3, 0x9b, 0x8d,
+	0x17, 0x87, 0x2e, 0xbe, 0xdb, 0xc4, 0xf9, 0x77, 0x86, 0x75, 0xc4, 0x94, 0xd8, 0x50, 0x67, 0x32,
+	0xfd, 0x12, 0xc6, 0x2d, 0x58, 0x6f, 0x79, 0x85, 0x1b, 0xb3, 0xe5, 0x88, 0xea, 0x25, 0x79, 0x0b,
+	0xc2, 0xeb, 0xa4, 0x58, 0x63, 0xe4, 0x1b, 0xcc, 0x0a, 0x5f, 0xf9, 0x4f, 0xbc, 0xf8, 0x77, 0x0f,
+	0x86, 0x67, 0xa2, 0x5a, 0x09, 0x94, 0x52, 0xd3, 0x24, 0x47, 0xb4, 0xd1, 0x7a, 0xd4, 0x0a, 0x24,
+	0x82, 0xc1, 0x6a, 0x8d, 0x52, 0xa2, 0x34, 0xe6, 0x01, 0xad, 0x45, 0x32, 0x83, 0x3d, 0x81, 0x69,
+	0x75, 0x8d, 0x02, 0x33, 0x1b, 0x80, 0xa9, 0x49, 0x40, 0x6f, 0xc3, 0xba, 0x72, 0xaa, 0x52, 0x49,
+	0xe1, 0x58, 0x81, 0x61, 0xb5, 0xa1, 0xf8, 0x5f, 0x0f, 0x06, 0xae, 0x19, 0x9a, 0x2d, 0xd6, 0x05,
+	0xca, 0xa7, 0x55, 0x91, 0xa1, 0x70, 0x89, 0xb4, 0x21, 0xf2, 0x31, 0x0c, 0x25, 0xa6, 0x2a, 0xaf,
+	0x98, 0x34, 0x5d, 0xda, 0x36, 0xf4, 0xb9, 0x85, 0x69, 0xa3, 0x27, 0x8f, 0x61, 0x50, 0x26, 0x9c,
+	0xe7, 0x6c, 0x15, 0xf5, 0x0c, 0xf5, 0xdd, 0x6e, 0xef, 0xe7, 0xa7, 0x56, 0x6b, 0x6b, 0x5a, 0x73,
+	0xc9, 0x07, 0x10, 0x54, 0x25, 0x32, 0x13, 0xeb, 0x78, 0x31, 0x76, 0x36, 0x3f, 0x96, 0xc8, 0xa8,
+	0x51, 0x4c, 0x4f, 0x60, 0xd2, 0xb6, 0xbc, 0xa7, 0xec, 0x87, 0xed, 0xb2, 0x8f, 0x17, 0x6f, 0x38,
+	0x1f, 0x27, 0x4c, 0x9d, 0x26, 0xbc, 0xdd, 0x85, 0x7f, 0x7c, 0x08, 0xb4, 0x67, 0xdd, 0x01, 0xa6,
+	0x27, 0xd1, 0x78, 0x09, 0xa9, 0x15, 0xf4, 0x4c, 0x96, 0xc9, 0xcd, 0x33, 0xbc, 0xc6, 0xc2, 0xb8,
+	0x0a, 0x69, 0x23, 0x93, 0x43, 0xf0, 0x73, 0xee, 0x12, 0x7b, 0xb3, 0x15, 0xe4, 0xfc, 0x84, 0xdb,
+	0x84, 0xfc, 0x9c, 0x6b, 0x52, 0xca, 0xa3, 0xe0, 0x2e, 0x69, 0x59, 0x93, 0x52, 0x43, 0x42, 0x1e,
+	0x85, 0x77, 0x49, 0x47, 0x35, 0x09, 0x39, 0xd9, 0x05, 0xbf, 0x60, 0x51, 0xff, 0xa0, 0x37, 0x0b,
+	0xa9, 0x5f, 0xb0, 0xe9, 0x63, 0x18, 0x9c, 0xf0, 0x97, 0xe5, 0xdf, 0x19, 0xbb, 0xb0, 0x95, 0xb0,
+	0x36, 0x5b, 0xbe, 0x9e, 0xd9, 0xd1, 0xab, 0x9b, 0xc5, 0x02, 0xfa, 0xb6, 0xe6, 0x64, 0x5e, 0x73,
+	0xec, 0x29, 0x8b, 0x3a, 0x1d, 0x99, 0xff, 0xac, 0x55, 0x36, 0x57, 0x4b, 0x9b, 0x3e, 0x01, 0xd8,
+	0x82, 0xaf, 0xb4, 0xe7, 0x9f, 0x1e, 0x8c, 0x29, 0xf2, 0x22, 0x49, 0xb1, 0x44, 0x66, 0xee, 0x0e,
+	0x2e, 0xaa, 0x8b, 0xe4, 0x22, 0x2f, 0x72, 0xb5, 0x71, 0x27, 0xac, 0x0d, 0x91, 0xf7, 0x01, 0x72,
+	0x79, 0xee, 0x6e, 0x31, 0xe3, 0x70, 0x48, 0x5b, 0x08, 0x79, 0x00, 0x7d, 0xe3, 0x5e, 0x9a, 0x6e,
+	0x8f, 0xa8, 0x93, 0xf4, 0x74, 0xfc, 0xb2, 0x66, 0x66, 0xd8, 0xcd, 0xb0, 0x8e, 0x68, 0x23, 0xeb,
+	0x88, 0x79, 0x25, 0x4d, 0x53, 0x43, 0xaa, 0x97, 0x71, 0x0e, 0x03, 0x77, 0x44, 0x08, 0x81, 0x40,
+	0x6d, 0x38, 0xba, 0x7c, 0xcc, 0x5a, 0x63, 0x2c, 0x29, 0xeb, 0x8b, 0xc2, 0xac, 0xc9, 0x17, 0x30,
+	0x11, 0xdb, 0x4c, 0xa4, 0x1b, 0x36, 0xe2, 0x6a, 0xd7, 0x4a, 0x92, 0x76, 0x78, 0xf1, 0x6f, 0x1e,
+	0x84, 0x27, 0x0a, 0x4b, 0x49, 0x1e, 0xc1, 0x84, 0x0b, 0x3c, 0x6f, 0x5d, 0xd9, 0xda, 0xc3, 0x9e,
+	0xf3, 0x70, 0x2e, 0x10, 0x35, 0x8f, 0x76, 0x48, 0xdd, 0x4b, 0xde, 0x5e, 0xc5, 0x5b, 0x80, 0x3c,
+	0x84, 0xdd, 0x46, 0x58, 0x56, 0x6b, 0xa6, 0xdc, 0xd5, 0x73, 0x0b, 0x8d, 0x7f, 0x85, 0x61, 0xed,
+	0x5f, 0x77, 0x2b, 0x67, 0x19, 0xde, 0xd4, 0xa7, 0xcb, 0x08, 0xba, 0xee, 0x4a, 0x24, 0x4c, 0xe6,
+	0xa6, 0x82, 0xb6, 0x91, 0x2d, 0x84, 0x7c, 0x0a, 0xa3, 0xf4, 0x32, 0x2f, 0x32, 0x81, 0xac, 0xce,
+	0xfd, 0x4e, 0xe4, 0x5b, 0x86, 0x3e, 0x21, 0x79, 0x66, 0x1a, 0x31, 0xa4, 0x7e, 0x9e, 0xc5, 0x02,
+	0x46, 0x3f, 0xad, 0x71, 0x6d, 0x23, 0xe8, 0xf6, 0xd8, 0xbb, 0xd3, 0xe3, 0x5b, 0x53, 0xe2, 0xdf,
+	0x9d, 0x92, 0x43, 0x08, 0x94, 0x40, 0xfb, 0xf8, 0xdc, 0x13, 0x88, 0x51, 0xc6, 0x7f, 0x7b, 0x00,
+	0xcb, 0x4b, 0x4c, 0xaf, 0x78, 0x95, 0x33, 0x45, 0x1e, 0x42, 0xf8, 0x42, 0x87, 0xe0, 0xea, 0xbe,
+	0xef, 0x8c, 0x9a, 0xb0, 0xa8, 0x55, 0x93, 0x8f, 0x60, 0xc0, 0x91, 0x65, 0xfa, 0xa6, 0xf4, 0xef,
+	0xcf, 0xb3, 0xd6, 0xeb, 0x47, 0xa1, 0x4c, 0x6e, 0xce, 0x44, 0x75, 0x61, 0x22, 0xf1, 0x68, 0x2d,
+	0xea, 0xb6, 0xad, 0x90, 0xa1, 0x48, 0x14, 0x66, 0xee, 0xa2, 0xdf, 0x02, 0xc6, 0x2e, 0x67, 0xc6,
+	0x2e, 0x74, 0x76, 0x56, 0xd4, 0x1a, 0xf7, 0x66, 0x44, 0x7d, 0xab, 0x71, 0xe2, 0xe2, 0x2f, 0x1f,
+	0x82, 0xb3, 0xe5, 0xd3, 0x63, 0xf2, 0x39, 0x0c, 0xdc, 0xc3, 0x4e, 0x26, 0x2e, 0x32, 0xf3, 0x79,
+	0x30, 0x7d, 0x50, 0xbf, 0x96, 0xdd, 0x67, 0x3f, 0xde, 0x21, 0x33, 0x80, 0x6f, 0x73, 0x99, 0xde,
+	0x6b, 0xd5, 0x91, 0xc8, 0x02, 0x26, 0xc7, 0xa8, 0xf4, 0x57, 0x86, 0x9d, 0xd9, 0x7a, 0xbe, 0x5b,
+	0xdf, 0x1d, 0x8d, 0x85, 0x61, 0xc4, 0x3b, 0xe4, 0x1b, 0x80, 0xe7, 0xc8, 0x32, 0xfb, 0xe4, 0x93,
+	0x77, 0x5e, 0xf2, 0x66, 0x4f, 0xdf, 0x6e, 0x8e, 0x4a, 0xe7, 0xd3, 0xe0, 0x33, 0x98, 0x68, 0xeb,
+	0xe6, 0xf9, 0xad, 0xab, 0x5d, 0x03, 0xdd, 0x00, 0xe3, 0x1d, 0xf2, 0x21, 0x04, 0xdf, 0xe7, 0x45,
+	0xf1, 0x7f, 0x69, 0xc4, 0x3b, 0x17, 0x7d, 0x23, 0x3e, 0xfa, 0x6f, 0x00, 0x69, 0x7e, 0xaa, 0xdf,
+	0x51, 0x09, 0x00, 0x00,
 }
 
 // Reference imports to suppress errors if they are not otherwise used.
@@ -646,6 +960,7 @@ type PCFGClient interface {
 	Disconnect(ctx context.Context, in *Empty, opts ...grpc.CallOption) (*Empty, error)
 	GetNextItems(ctx context.Context, in *NextRequest, opts ...grpc.CallOption) (*Items, error)
 	SendResult(ctx context.Context, in *CrackingResponse, opts ...grpc.CallOption) (*ResultResponse, error)
+	SendProgress(ctx context.Context, in *Progress, opts ...grpc.CallOption) (*Empty, error)
 	Kill(ctx context.Context, in *Empty, opts ...grpc.CallOption) (*Empty, error)
 }
 
@@ -693,6 +1008,15 @@ func (c *pCFGClient) SendResult(ctx context.Context, in *CrackingResponse, opts
 	return out, nil
 }
 
+func (c *pCFGClient) SendProgress(ctx context.Context, in *Progress, opts ...grpc.CallOption) (*Empty, error) {
+	out := new(Empty)
+	err := c.cc.Invoke(ctx, "/proto.PCFG/SendProgress", in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
 func (c *pCFGClient) Kill(ctx context.Context, in *Empty, opts ...grpc.CallOption) (*Empty, error) {
 	out := new(Empty)
 	err := c.cc.Invoke(ctx, "/proto.PCFG/Kill", in, out, opts...)
@@ -708,6 +1032,7 @@ type PCFGServer interface {
 	Disconnect(context.Context, *Empty) (*Empty, error)
 	GetNextItems(context.Context, *NextRequest) (*Items, error)
 	SendResult(context.Context, *CrackingResponse) (*ResultResponse, error)
+	SendProgress(context.Context, *Progress) (*Empty, error)
 	Kill(context.Context, *Empty) (*Empty, error)
 }
 
@@ -787,6 +1112,24 @@ func _PCFG_SendResult_Handler(srv interface{}, ctx context.Context, dec func(int
 	return interceptor(ctx, in, info, handler)
 }
 
+func _PCFG_SendProgress_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(Progress)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(PCFGServer).SendProgress(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: "/proto.PCFG/SendProgress",
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(PCFGServer).SendProgress(ctx, req.(*Progress))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
 func _PCFG_Kill_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
 	in := new(Empty)
 	if err := dec(in); err != nil {
@@ -825,6 +1168,10 @@ var _PCFG_serviceDesc = grpc.ServiceDesc{
 			MethodName: "SendResult",
 			Handler:    _PCFG_SendResult_Handler,
 		},
+		{
+			MethodName: "SendProgress",
+			Handler:    _PCFG_SendProgress_Handler,
+		},
 		{
 			MethodName: "Kill",
 			Handler:    _PCFG_Kill_Handler,
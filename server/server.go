@@ -13,18 +13,21 @@ import (
 	"google.golang.org/grpc/peer"
 	"net"
 	"os"
+	"sync"
 	"sync/atomic"
 	"time"
 	"unicode"
 )
 
 type Service struct {
-	mng                *manager.Manager
-	args               manager.InputArgs
-	remainingHashes    map[string]struct{}
-	completedHashes    map[string]string
-	generatorCh        <-chan manager.PreTerminalItem
-	returnedChunks     *list.List
+	mng             *manager.Manager
+	args            manager.InputArgs
+	remainingHashes map[string]struct{}
+	completedHashes map[string]string
+	generatorCh     <-chan manager.PreTerminalItem
+	returnedChunks  *list.List
+	// mu guards clients, progress of client is sent concurrently with other requests
+	mu                 sync.Mutex
 	clients            map[string]ClientInfo
 	chunkId            uint32
 	endCracking        chan bool
@@ -33,10 +36,13 @@ type Service struct {
 	bandwidth          uint64
 	start              time.Time
 	forceStop          bool
+	// exclusion drops already tried guesses generated by server
+	exclusion *manager.Exclusion
 }
 
 type Chunk struct {
 	Id             uint32
+	ItemIds        []uint64
 	PreTerminals   []*pb.TreeItem
 	Terminals      []string
 	TerminalsCount uint64
@@ -58,9 +64,36 @@ type ClientInfo struct {
 	PreviousTerminals uint64
 	Total             uint64
 	Speed             float64
+	// MeasuredSpeed is speed reported by cracker of client in guesses per second
+	MeasuredSpeed   float64
+	RecoveredHashes uint64
+	LastProgress    time.Time
+}
+
+// client returns copy of client info
+func (s *Service) client(addr string) (ClientInfo, bool) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	clientInfo, ok := s.clients[addr]
+	return clientInfo, ok
+}
+
+// updateClient modifies info of connected client, it returns false if client isn't connected
+func (s *Service) updateClient(addr string, update func(clientInfo *ClientInfo)) bool {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	clientInfo, ok := s.clients[addr]
+	if !ok {
+		return false
+	}
+	update(&clientInfo)
+	s.clients[addr] = clientInfo
+	return true
 }
 
 func (s *Service) DebugClients() {
+	s.mu.Lock()
+	defer s.mu.Unlock()
 	totalSpeed := 0.0
 	for _, client := range s.clients {
 		totalSpeed += client.Speed
@@ -122,10 +155,32 @@ func (s *Service) Load(args manager.InputArgs) error {
 		}
 	}
 
+	if len(s.args.ExcludePotfiles) > 0 || len(s.args.ExcludeWordlists) > 0 {
+		if !s.args.GenerateTerminals {
+			logrus.Warn("excluded guesses are applied only with generated terminals")
+		}
+		hashes := make([]string, 0, len(s.remainingHashes))
+		for h := range s.remainingHashes {
+			hashes = append(hashes, h)
+		}
+		exclusion, err := manager.LoadExclusion(s.args.ExcludePotfiles, s.args.ExcludeWordlists, hashes)
+		if err != nil {
+			return err
+		}
+		logrus.Infof("loaded %d excluded guesses", exclusion.Len())
+		s.exclusion = exclusion
+	}
+
 	s.mng = manager.NewManager(s.args.RulesFolder)
 	if err := s.mng.Load(); err != nil {
 		return err
 	}
+	if s.args.RestoreFile != "" {
+		if err := s.mng.Generator.LoadCheckpoint(s.args.RestoreFile); err != nil {
+			return err
+		}
+		s.processedTerminals = s.mng.Generator.Generated
+	}
 	s.generatorCh = s.mng.Generator.RunForServer(&args)
 	return nil
 }
@@ -162,7 +217,9 @@ func (s *Service) Connect(ctx context.Context, req *pb.Empty) (*pb.ConnectRespon
 	client := ClientInfo{
 		Addr: p.Addr.String(),
 	}
+	s.mu.Lock()
 	s.clients[client.Addr] = client
+	s.mu.Unlock()
 	var hashList []string
 	for k := range s.remainingHashes {
 		hashList = append(hashList, k)
@@ -183,7 +240,7 @@ func (s *Service) Disconnect(ctx context.Context, req *pb.Empty) (*pb.Empty, err
 	if !ok {
 		return &pb.Empty{}, errors.New("no peer")
 	}
-	clientInfo, ok := s.clients[p.Addr.String()]
+	clientInfo, ok := s.client(p.Addr.String())
 	if !ok {
 		return &pb.Empty{}, errors.New("client wasn't connected")
 	}
@@ -227,6 +284,10 @@ loop:
 	}
 	var preTerminals []*pb.TreeItem
 	var guesses []string
+	itemIds := make([]uint64, 0, len(chunkItems))
+	for _, ch := range chunkItems {
+		itemIds = append(itemIds, ch.Id)
+	}
 	if !s.args.GenerateTerminals {
 		preTerminals = make([]*pb.TreeItem, 0, len(chunkItems))
 		for _, ch := range chunkItems {
@@ -237,11 +298,21 @@ loop:
 		for _, ch := range chunkItems {
 			guesses = append(guesses, s.mng.Generator.Pcfg.ListTerminalsToSlice(ch.Item, ch.Count)...)
 		}
+		if s.exclusion != nil {
+			kept := guesses[:0]
+			for _, guess := range guesses {
+				if s.exclusion.Keep([]byte(guess)) {
+					kept = append(kept, guess)
+				}
+			}
+			guesses = kept
+		}
 	}
 	timeGen := time.Now().Sub(startTime)
 	s.timeGeneration += timeGen
 	return Chunk{
 		Id:             atomic.AddUint32(&s.chunkId, 1),
+		ItemIds:        itemIds,
 		PreTerminals:   preTerminals,
 		Terminals:      guesses,
 		TerminalsCount: total,
@@ -255,26 +326,33 @@ func (s *Service) GetNextItems(ctx context.Context, req *pb.NextRequest) (*pb.It
 		return &pb.Items{}, errors.New("no peer")
 	}
 	then := time.Now()
-	clientInfo, ok := s.clients[p.Addr.String()]
+	clientInfo, ok := s.client(p.Addr.String())
 	if !ok {
 		return nil, errors.New("client is not connected")
 	}
 	chunkSize := s.args.ChunkStartSize
+	speed := clientInfo.Speed
 	if req.Terminals != 0 {
 		chunkSize = req.Terminals
 	} else {
 		if !clientInfo.EndTime.IsZero() && clientInfo.PreviousTerminals != 0 {
-			clientInfo.Speed = float64(clientInfo.PreviousTerminals) / clientInfo.EndTime.Sub(clientInfo.StartTime).Seconds()
-			chunkSize = uint64(clientInfo.Speed * s.args.ChunkDuration.Seconds())
+			speed = float64(clientInfo.PreviousTerminals) / clientInfo.EndTime.Sub(clientInfo.StartTime).Seconds()
+			// speed measured by cracker during last chunk doesn't include time of generation and communication
+			if clientInfo.MeasuredSpeed > 0 && clientInfo.LastProgress.After(clientInfo.StartTime) {
+				speed = clientInfo.MeasuredSpeed
+			}
+			chunkSize = uint64(speed * s.args.ChunkDuration.Seconds())
 		}
 	}
 	chunk, endGen := s.GetNextChunk(chunkSize)
 	if endGen && chunk.TerminalsCount == 0 {
 		return &pb.Items{}, nil
 	}
-	clientInfo.ActualChunk = chunk
-	clientInfo.StartTime = time.Now()
-	s.clients[p.Addr.String()] = clientInfo
+	s.updateClient(p.Addr.String(), func(clientInfo *ClientInfo) {
+		clientInfo.Speed = speed
+		clientInfo.ActualChunk = chunk
+		clientInfo.StartTime = time.Now()
+	})
 	items := &pb.Items{
 		PreTerminals:   chunk.PreTerminals,
 		Terminals:      chunk.Terminals,
@@ -288,6 +366,21 @@ func (s *Service) GetNextItems(ctx context.Context, req *pb.NextRequest) (*pb.It
 	return items, nil
 }
 
+// Stop immediately stops server, unfinished chunks stay pending in checkpoint
+func (s *Service) Stop() {
+	s.mng.Generator.Stop()
+	s.forceStop = true
+	s.endCracking <- true
+}
+
+// SaveCheckpoint saves state of generation, see manager.Generator.SaveCheckpoint
+func (s *Service) SaveCheckpoint() error {
+	if s.args.CheckpointFile == "" {
+		return nil
+	}
+	return s.mng.Generator.SaveCheckpoint(s.args.CheckpointFile)
+}
+
 func (s *Service) Kill(ctx context.Context, req *pb.Empty) (*pb.Empty, error) {
 	s.forceStop = true
 	s.endCracking <- true
@@ -299,7 +392,7 @@ func (s *Service) SendResult(ctx context.Context, in *pb.CrackingResponse) (*pb.
 	if !ok {
 		return &pb.ResultResponse{End: false}, errors.New("no peer")
 	}
-	clientInfo, ok := s.clients[p.Addr.String()]
+	clientInfo, ok := s.client(p.Addr.String())
 	if !ok {
 		return nil, errors.New("client is not connected")
 	}
@@ -307,12 +400,15 @@ func (s *Service) SendResult(ctx context.Context, in *pb.CrackingResponse) (*pb.
 		delete(s.remainingHashes, hash)
 		s.completedHashes[hash] = password
 	}
-	clientInfo.EndTime = time.Now()
+	s.mng.Generator.Complete(clientInfo.ActualChunk.ItemIds...)
 	s.processedTerminals += clientInfo.ActualChunk.TerminalsCount
-	clientInfo.Total += clientInfo.ActualChunk.TerminalsCount
-	clientInfo.PreviousTerminals = clientInfo.ActualChunk.TerminalsCount
-	clientInfo.ActualChunk = Chunk{}
-	s.clients[p.Addr.String()] = clientInfo
+	s.updateClient(p.Addr.String(), func(c *ClientInfo) {
+		c.EndTime = time.Now()
+		c.Total += c.ActualChunk.TerminalsCount
+		c.PreviousTerminals = c.ActualChunk.TerminalsCount
+		c.ActualChunk = Chunk{}
+		clientInfo = *c
+	})
 
 	logrus.Infof("result from %s: %d in %f seconds", clientInfo.Addr, len(in.Hashes), clientInfo.EndTime.Sub(clientInfo.StartTime).Seconds())
 	if (len(s.remainingHashes) == 0 && s.args.HashFile != "") || s.processedTerminals >= s.mng.Generator.Generated {
@@ -322,6 +418,32 @@ func (s *Service) SendResult(ctx context.Context, in *pb.CrackingResponse) (*pb.
 	return &pb.ResultResponse{End: false}, nil
 }
 
+// SendProgress saves status of cracker of client, its speed is used for size of next chunk
+func (s *Service) SendProgress(ctx context.Context, in *pb.Progress) (*pb.Empty, error) {
+	p, ok := peer.FromContext(ctx)
+	if !ok {
+		return &pb.Empty{}, errors.New("no peer")
+	}
+	var recovered bool
+	ok = s.updateClient(p.Addr.String(), func(clientInfo *ClientInfo) {
+		recovered = in.RecoveredHashes != clientInfo.RecoveredHashes
+		clientInfo.MeasuredSpeed = in.Speed
+		clientInfo.RecoveredHashes = in.RecoveredHashes
+		clientInfo.LastProgress = time.Now()
+	})
+	if !ok {
+		return nil, errors.New("client is not connected")
+	}
+	// every tick is logged only in debug mode, progress with new recovered hashes is logged always
+	log := logrus.Debugf
+	if recovered {
+		log = logrus.Infof
+	}
+	log("progress of %s: %.0f guesses/s, guesses: %d, recovered: %d/%d",
+		p.Addr.String(), in.Speed, in.Guesses, in.RecoveredHashes, in.TotalHashes)
+	return &pb.Empty{}, nil
+}
+
 func readLines(path string) ([]string, error) {
 	file, err := os.Open(path)
 	if err != nil {